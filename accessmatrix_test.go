@@ -1,36 +1,28 @@
 package integration
 
 import (
-	"os"
+	"context"
+	"sort"
 	"testing"
-	"time"
 
 	"github.com/btcsuite/btcutil/base58"
-	"github.com/golang/protobuf/proto"
+	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/ozontech/allure-go/pkg/framework/runner"
 	"github.com/stretchr/testify/assert"
-	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/acl"
+	"github.com/tickets-dao/integration/fixtures"
 	"github.com/tickets-dao/integration/utils"
 )
 
-const acl = "acl"
-const issuer = "issuer"
-
-const (
-	addUserFn       = "addUser"
-	addRightsFn     = "addRights"
-	removeRightsFn  = "removeRights"
-	getAccOpRightFn = "getAccountOperationRight"
-)
+const addUserFn = "addUser"
 
 // TestAclRights creates user in acl chaincode, adds rights, removes rights, checks all operations is done
 func TestAclRights(t *testing.T) {
 	runner.Run(t, "Add and remove rights", func(t provider.T) {
 		t.Tags("positive", "acl")
-
-		t.NewStep("Prepare service to connect with API hlf proxy service")
-		hlfProxy := utils.NewHlfProxyService(os.Getenv(utils.EnvHlfProxyURL), os.Getenv(utils.EnvHlfProxyAuthToken))
+		ctx := context.Background()
+		aclClient := acl.NewClient(client)
 
 		t.NewStep("Generate private key for user")
 		_, userFromEd25519PublicKey, err := utils.GeneratePrivateAndPublicKey()
@@ -44,49 +36,240 @@ func TestAclRights(t *testing.T) {
 		assert.NoError(t, err)
 
 		t.NewStep("Invoke chaincode acl with method addUser, and create user")
-		_, err = hlfProxy.Invoke("acl", "addUser", publicKeyBase58, "test", "testuser", "true")
+		_, err = client.Invoke(ctx, "acl", "addUser", publicKeyBase58, "test", "testuser", "true")
 		t.Assert().NoError(err)
-		time.Sleep(utils.BatchTransactionTimeout)
 
 		t.NewStep("Query chaincode acl with method checkKeys")
-		_, err = hlfProxy.Query("acl", "checkKeys", publicKeyBase58)
+		_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+			return client.Query(ctx, "acl", "checkKeys", publicKeyBase58)
+		}, nil)
 		t.Assert().NoError(err)
 
-		t.NewStep("Invoke chaincode `" + acl + "` with method `" + addUserFn + "`, and create user")
-		_, err = hlfProxy.Invoke(acl, addUserFn, userAddress, "test", "testuser", "true")
+		t.NewStep("Invoke chaincode `" + acl.ChannelName + "` with method `" + addUserFn + "`, and create user")
+		_, err = client.Invoke(ctx, acl.ChannelName, addUserFn, userAddress, "test", "testuser", "true")
 		t.Assert().NoError(err)
-		time.Sleep(utils.BatchTransactionTimeout)
 
 		t.NewStep("Query chaincode `acl` with method checkKeys")
-		_, err = hlfProxy.Query(acl, "checkKeys", userAddress)
+		_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+			return client.Query(ctx, acl.ChannelName, "checkKeys", userAddress)
+		}, nil)
 		t.Assert().NoError(err)
 
-		const testOperation = "testOperation"
+		right := acl.Right{
+			Channel:   acl.ChannelName,
+			Chaincode: acl.ChannelName,
+			Role:      "issuer",
+			Operation: "testOperation",
+			Address:   userAddress,
+		}
 
-		t.NewStep("Invoke chaincode `" + acl + "` with method `" + addRightsFn + "` and grant right")
-		_, err = hlfProxy.Invoke(acl, addRightsFn, acl, acl, issuer, testOperation, userAddress)
+		t.NewStep("Invoke chaincode `" + acl.ChannelName + "` with method `" + acl.AddRightsFn + "` and grant right")
+		_, err = aclClient.AddRights(ctx, right)
 		t.Assert().NoError(err)
-		time.Sleep(utils.BatchTransactionTimeout)
 
-		t.NewStep("Query chaincode `" + acl + "` with method `" + getAccOpRightFn + "` rights is set")
-		rsp, err := hlfProxy.Query(acl, getAccOpRightFn, acl, acl, issuer, testOperation, userAddress)
-		t.Assert().NoError(err)
-		var haveRight pb.HaveRight
-		err = proto.Unmarshal(rsp.Payload, &haveRight)
+		t.NewStep("Query chaincode `" + acl.ChannelName + "` with method `" + acl.GetAccountOperationRightFn + "` rights is set")
+		t.Assert().NoError(aclClient.WaitRight(ctx, right, true))
+		haveRight, err := aclClient.HasRight(ctx, right)
 		t.Assert().NoError(err)
-		t.Assert().Equal(true, haveRight.HaveRight)
+		t.Assert().Equal(true, haveRight)
 
-		t.NewStep("Invoke chaincode `" + acl + "` with method `" + removeRightsFn + "` and remove right")
-		_, err = hlfProxy.Invoke(acl, removeRightsFn, acl, acl, issuer, testOperation, userAddress)
+		t.NewStep("Invoke chaincode `" + acl.ChannelName + "` with method `" + acl.RemoveRightsFn + "` and remove right")
+		_, err = aclClient.RemoveRights(ctx, right)
 		t.Assert().NoError(err)
-		time.Sleep(utils.BatchTransactionTimeout)
 
-		t.NewStep("Query chaincode `" + acl + "` with method `" + getAccOpRightFn + "` rights is not set")
-		rsp2, err := hlfProxy.Query(acl, getAccOpRightFn, acl, acl, issuer, testOperation, userAddress)
+		t.NewStep("Query chaincode `" + acl.ChannelName + "` with method `" + acl.GetAccountOperationRightFn + "` rights is not set")
+		t.Assert().NoError(aclClient.WaitRight(ctx, right, false))
+		haveRight, err = aclClient.HasRight(ctx, right)
 		t.Assert().NoError(err)
-		var r pb.HaveRight
-		err = proto.Unmarshal(rsp2.Payload, &r)
-		t.Assert().NoError(err)
-		t.Assert().Equal(false, r.HaveRight)
+		t.Assert().Equal(false, haveRight)
+	})
+}
+
+// TestAccessMatrix - grant rights of declarative role/operation/address grid and check every cell
+// by single right, account rights, operation rights, accounts and operations of acl
+func TestAccessMatrix(t *testing.T) {
+	runner.Run(t, "access matrix of roles, operations and addresses", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("Every cell of access matrix has right exactly when it is granted, revoke changes only its cell")
+		t.Tags("positive", "acl")
+
+		const usersCount = 3
+
+		var (
+			ctx       = context.Background()
+			aclClient = acl.NewClient(client)
+			users     = make([]*fixtures.Identity, usersCount)
+
+			// matrix - granted rights by role and operation, one column per user
+			matrix = map[string]map[string][usersCount]bool{
+				"issuer": {
+					"emitOperation":   {true, false, false},
+					"freezeOperation": {true, true, false},
+				},
+				"auditor": {
+					"emitOperation":   {false, false, false},
+					"freezeOperation": {false, true, true},
+				},
+			}
+			// added - rights granted by test by acl.Right.String, cleanup removes only them
+			added = make(map[string]acl.Right)
+		)
+
+		// cell - right of user to operation of role in acl chaincode
+		cell := func(role, operation string, user int) acl.Right {
+			return acl.Right{
+				Channel:   acl.ChannelName,
+				Chaincode: acl.ChannelName,
+				Role:      role,
+				Operation: operation,
+				Address:   users[user].Address,
+			}
+		}
+
+		// forEachCell - call f for every cell of matrix in stable order
+		forEachCell := func(f func(right acl.Right, granted bool)) {
+			roles := make([]string, 0, len(matrix))
+			for role := range matrix {
+				roles = append(roles, role)
+			}
+			sort.Strings(roles)
+			for _, role := range roles {
+				operations := make([]string, 0, len(matrix[role]))
+				for operation := range matrix[role] {
+					operations = append(operations, operation)
+				}
+				sort.Strings(operations)
+				for _, operation := range operations {
+					for user, granted := range matrix[role][operation] {
+						f(cell(role, operation, user), granted)
+					}
+				}
+			}
+		}
+
+		// sorted - rights as sorted strings to compare regardless of order
+		sorted := func(rights []acl.Right) []string {
+			result := make([]string, 0, len(rights))
+			for _, right := range rights {
+				result = append(result, right.String())
+			}
+			sort.Strings(result)
+			return result
+		}
+
+		// checkMatrix - every cell has right as declared, account and operation rights of users match matrix
+		checkMatrix := func(sCtx provider.StepCtx) {
+			expectedByAddress := make(map[string][]acl.Right)
+			expectedByOperation := make(map[string][]acl.Right)
+			forEachCell(func(right acl.Right, granted bool) {
+				haveRight, err := aclClient.HasRight(ctx, right)
+				sCtx.Require().NoError(err)
+				sCtx.Assert().Equal(granted, haveRight, "right %s", right)
+				if granted {
+					expectedByAddress[right.Address] = append(expectedByAddress[right.Address], right)
+					expectedByOperation[right.Role+"/"+right.Operation] = append(expectedByOperation[right.Role+"/"+right.Operation], right)
+				}
+			})
+
+			for _, user := range users {
+				rights, err := aclClient.GetAccountAllRights(ctx, user.Address)
+				sCtx.Require().NoError(err)
+				sCtx.Assert().Equal(user.Address, acl.EncodeAddress(rights.Address))
+				actual := make([]acl.Right, 0, len(rights.Rights))
+				for _, right := range rights.Rights {
+					actual = append(actual, acl.FromProto(right))
+				}
+				sCtx.Assert().Equal(sorted(expectedByAddress[user.Address]), sorted(actual), "rights of %s", user.Address)
+			}
+
+			addresses := make(map[string]bool, len(users))
+			for _, user := range users {
+				addresses[user.Address] = true
+			}
+			for role, operations := range matrix {
+				for operation := range operations {
+					rights, err := aclClient.GetOperationAllRights(ctx, acl.ChannelName, acl.ChannelName, role, operation)
+					sCtx.Require().NoError(err)
+					sCtx.Assert().Equal(operation, rights.OperationName)
+					var actual []acl.Right
+					for _, right := range rights.Rights {
+						if r := acl.FromProto(right); addresses[r.Address] {
+							actual = append(actual, r)
+						}
+					}
+					sCtx.Assert().Equal(sorted(expectedByOperation[role+"/"+operation]), sorted(actual), "rights to %s of %s", operation, role)
+				}
+			}
+		}
+
+		t.WithNewStep("Register users in acl", func(sCtx provider.StepCtx) {
+			for i := range users {
+				var err error
+				users[i], err = fixtures.NewUser(ctx, client)
+				sCtx.Require().NoError(err)
+			}
+		})
+
+		t.WithNewStep("Grant rights of matrix", func(sCtx provider.StepCtx) {
+			t.Cleanup(func() {
+				keys := make([]string, 0, len(added))
+				for key := range added {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					if _, err := aclClient.RemoveRights(ctx, added[key]); err != nil {
+						t.Errorf("remove right %s: %v", key, err)
+					}
+				}
+			})
+
+			forEachCell(func(right acl.Right, granted bool) {
+				if !granted {
+					return
+				}
+				_, err := aclClient.AddRights(ctx, right)
+				sCtx.Require().NoError(err)
+				added[right.String()] = right
+				sCtx.Require().NoError(aclClient.WaitRight(ctx, right, true))
+			})
+		})
+
+		t.WithNewStep("Check every cell of matrix", checkMatrix)
+
+		t.WithNewStep("Accounts and operations of acl include users and operations of matrix", func(sCtx provider.StepCtx) {
+			accounts, err := aclClient.GetAllAccounts(ctx)
+			sCtx.Require().NoError(err)
+			registered := make(map[string]bool, len(accounts.Addresses))
+			for _, address := range accounts.Addresses {
+				registered[acl.EncodeAddress(address)] = true
+			}
+			for _, user := range users {
+				sCtx.Assert().True(registered[user.Address], "account %s", user.Address)
+			}
+
+			operations, err := aclClient.GetAllOperations(ctx)
+			sCtx.Require().NoError(err)
+			for _, role := range matrix {
+				for operation, granted := range role {
+					if granted != [usersCount]bool{} {
+						sCtx.Assert().Contains(operations.Operations, operation)
+					}
+				}
+			}
+		})
+
+		t.WithNewStep("Revoke single right and check matrix again", func(sCtx provider.StepCtx) {
+			right := cell("issuer", "freezeOperation", 1)
+			_, err := aclClient.RemoveRights(ctx, right)
+			sCtx.Require().NoError(err)
+			delete(added, right.String())
+			sCtx.Require().NoError(aclClient.WaitRight(ctx, right, false))
+
+			row := matrix["issuer"]["freezeOperation"]
+			row[1] = false
+			matrix["issuer"]["freezeOperation"] = row
+			checkMatrix(sCtx)
+		})
 	})
 }
@@ -0,0 +1,324 @@
+// Package accounting - reconciliation of balances with accounting records of batch events.
+// Ledger consumes pb.AccountingRecord of transactions executed during test run, builds expected balances
+// by channel, token and address and compares them with balanceOf, allowedBalanceOf and industrialBalanceOf.
+// Without batch events ledger is built from balances of opened addresses, see Collect
+package accounting
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tickets-dao/integration/amount"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+const (
+	aclChaincode   = "acl"
+	batchExecuteFn = "batchExecute"
+)
+
+// Discrepancy - expected balance built from accounting records differs from balance in channel
+type Discrepancy struct {
+	Channel  string
+	Token    string
+	Address  string
+	Expected *big.Int
+	Actual   *big.Int
+}
+
+func (d Discrepancy) String() string {
+	return fmt.Sprintf("%s %s %s: expected %s, actual %s",
+		d.Channel, d.Token, d.Address, amount.String(d.Expected), amount.String(d.Actual))
+}
+
+// balanceKey - balance of address in token of channel
+type balanceKey struct {
+	channel string
+	token   string
+	address string
+}
+
+// entry - change of balance by accounting record
+type entry struct {
+	key   balanceKey
+	value *big.Int
+}
+
+// Ledger - expected balances, is safe for concurrent use
+type Ledger struct {
+	mu       sync.Mutex
+	balances map[balanceKey]*big.Int
+	// pending - transactions to collect by channel
+	pending map[string][]string
+	// consumed - events already added, event is counted once
+	consumed map[string]bool
+}
+
+// NewLedger - empty ledger, balances of addresses without records are expected to be zero
+func NewLedger() *Ledger {
+	return &Ledger{
+		balances: make(map[balanceKey]*big.Int),
+		pending:  make(map[string][]string),
+		consumed: make(map[string]bool),
+	}
+}
+
+// Hook - track transactions invoked by client in token channels, add it with utils.WithHook
+func (l *Ledger) Hook() utils.Hook {
+	return func(_ context.Context, call *utils.Call) {
+		if call.RequestType != "invoke" || call.Err != nil || call.Response == nil {
+			return
+		}
+		if call.Request.ChaincodeID == aclChaincode || call.Request.Fcn == batchExecuteFn {
+			return
+		}
+		l.Track(call.Request.ChaincodeID, call.Response.TransactionID)
+	}
+}
+
+// Track - transaction of channel which records are added by Collect
+func (l *Ledger) Track(channel, txID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending[channel] = append(l.pending[channel], txID)
+}
+
+// Collect - wait for batch events of tracked transactions and add their records.
+// If client is created without utils.WithBatchEvents, records can't be read: tracked transactions are dropped and
+// expected balances are replaced by balances in channels, so test must wait until its transactions change balances
+func (l *Ledger) Collect(ctx context.Context, client *utils.Client) error {
+	if !client.BatchEvents() {
+		return l.collectBalances(ctx, client)
+	}
+
+	l.mu.Lock()
+	pending := l.pending
+	l.pending = make(map[string][]string)
+	l.mu.Unlock()
+
+	for channel, txIDs := range pending {
+		for len(txIDs) != 0 {
+			event, err := client.TxResult(ctx, channel, txIDs[0])
+			if err == nil {
+				err = l.AddEvent(channel, event)
+			}
+			if err != nil {
+				pending[channel] = txIDs
+				l.requeue(pending)
+				return err
+			}
+			txIDs = txIDs[1:]
+		}
+		delete(pending, channel)
+	}
+	return nil
+}
+
+// collectBalances - take balances of every address known to ledger as expected balances
+func (l *Ledger) collectBalances(ctx context.Context, client *utils.Client) error {
+	l.mu.Lock()
+	l.pending = make(map[string][]string)
+	keys := make([]balanceKey, 0, len(l.balances))
+	for key := range l.balances {
+		keys = append(keys, key)
+	}
+	l.mu.Unlock()
+
+	for _, key := range keys {
+		balance, err := queryBalance(ctx, client, key.channel, key.token, key.address)
+		if err != nil {
+			return err
+		}
+
+		l.mu.Lock()
+		l.balances[key] = balance
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+// requeue - return transactions which aren't collected yet
+func (l *Ledger) requeue(pending map[string][]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for channel, txIDs := range pending {
+		l.pending[channel] = append(l.pending[channel], txIDs...)
+	}
+}
+
+// AddEvent - add records of batch event of channel, failed transaction has no records. Event is counted once.
+// If any record has malformed address, no record is added and event can be added again
+func (l *Ledger) AddEvent(channel string, event *pb.BatchTxEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := channel + "/" + hex.EncodeToString(event.Id)
+	if l.consumed[id] {
+		return nil
+	}
+
+	if event.Error == nil {
+		entries, err := parseRecords(channel, event.Accounting)
+		if err != nil {
+			return fmt.Errorf("tx %s: %w", hex.EncodeToString(event.Id), err)
+		}
+		l.apply(entries)
+	}
+	l.consumed[id] = true
+	return nil
+}
+
+// AddRecords - add accounting records of channel, no record is added if any of them has malformed address
+func (l *Ledger) AddRecords(channel string, records ...*pb.AccountingRecord) error {
+	entries, err := parseRecords(channel, records)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.apply(entries)
+	return nil
+}
+
+// apply - change balances by entries, l.mu must be held
+func (l *Ledger) apply(entries []entry) {
+	for _, e := range entries {
+		l.balances[e.key] = amount.Add(l.balances[e.key], e.value)
+	}
+}
+
+// parseRecords - changes of balances of channel by records: amount is moved from sender to recipient
+func parseRecords(channel string, records []*pb.AccountingRecord) ([]entry, error) {
+	entries := make([]entry, 0, 2*len(records))
+	for _, record := range records {
+		sender, err := utils.EncodeAddress(record.Sender)
+		if err != nil {
+			return nil, fmt.Errorf("sender of record %s: %w", record.Reason, err)
+		}
+		recipient, err := utils.EncodeAddress(record.Recipient)
+		if err != nil {
+			return nil, fmt.Errorf("recipient of record %s: %w", record.Reason, err)
+		}
+
+		value := amount.FromBytes(record.Amount)
+		token := strings.ToUpper(record.Token)
+		if sender != "" {
+			entries = append(entries, entry{
+				key:   balanceKey{channel: channel, token: token, address: sender},
+				value: amount.Sub(nil, value),
+			})
+		}
+		if recipient != "" {
+			entries = append(entries, entry{
+				key:   balanceKey{channel: channel, token: token, address: recipient},
+				value: value,
+			})
+		}
+	}
+	return entries, nil
+}
+
+// Open - take current balances of addresses in token of channel as opening balances,
+// addresses used by previous tests must be opened before their records are added
+func (l *Ledger) Open(ctx context.Context, client *utils.Client, channel, token string, addresses ...string) error {
+	token = strings.ToUpper(token)
+	for _, address := range addresses {
+		balance, err := queryBalance(ctx, client, channel, token, address)
+		if err != nil {
+			return err
+		}
+
+		key := balanceKey{channel: channel, token: token, address: address}
+		l.mu.Lock()
+		l.balances[key] = amount.Add(l.balances[key], balance)
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+// Expected - expected balance of address in token of channel
+func (l *Ledger) Expected(channel, token, address string) *big.Int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return amount.Add(l.balances[balanceKey{channel: channel, token: strings.ToUpper(token), address: address}])
+}
+
+// Total - sum of expected balances of every address in token of channel
+func (l *Ledger) Total(channel, token string) *big.Int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	token = strings.ToUpper(token)
+	var total *big.Int
+	for key, balance := range l.balances {
+		if key.channel == channel && key.token == token {
+			total = amount.Add(total, balance)
+		}
+	}
+	return amount.Add(total)
+}
+
+// Reconcile - compare every expected balance with balance in channel, returns all discrepancies sorted by
+// channel, token and address. Error is returned only if balance can't be queried
+func (l *Ledger) Reconcile(ctx context.Context, client *utils.Client) ([]Discrepancy, error) {
+	l.mu.Lock()
+	keys := make([]balanceKey, 0, len(l.balances))
+	expected := make(map[balanceKey]*big.Int, len(l.balances))
+	for key, balance := range l.balances {
+		keys = append(keys, key)
+		expected[key] = amount.Add(balance)
+	}
+	l.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].channel != keys[j].channel {
+			return keys[i].channel < keys[j].channel
+		}
+		if keys[i].token != keys[j].token {
+			return keys[i].token < keys[j].token
+		}
+		return keys[i].address < keys[j].address
+	})
+
+	var discrepancies []Discrepancy
+	for _, key := range keys {
+		actual, err := queryBalance(ctx, client, key.channel, key.token, key.address)
+		if err != nil {
+			return nil, err
+		}
+		if !amount.Equal(expected[key], actual) {
+			discrepancies = append(discrepancies, Discrepancy{
+				Channel:  key.channel,
+				Token:    key.token,
+				Address:  key.address,
+				Expected: expected[key],
+				Actual:   actual,
+			})
+		}
+	}
+	return discrepancies, nil
+}
+
+// queryBalance - own token of channel by balanceOf, group of own industrial token by industrialBalanceOf,
+// token of other channel by allowedBalanceOf. Symbol of token chaincode is channel name in upper case
+func queryBalance(ctx context.Context, client *utils.Client, channel, token, address string) (*big.Int, error) {
+	symbol := strings.ToUpper(channel)
+	switch {
+	case token == symbol:
+		return client.BalanceOf(ctx, channel, address)
+	case strings.HasPrefix(token, symbol+"_"):
+		balances, err := client.IndustrialBalanceOf(ctx, channel, address)
+		if err != nil {
+			return nil, err
+		}
+		return amount.Add(balances[strings.TrimPrefix(token, symbol+"_")]), nil
+	default:
+		return client.AllowedBalanceOf(ctx, channel, address, token)
+	}
+}
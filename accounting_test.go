@@ -0,0 +1,183 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/accounting"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/industrial"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/swap"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// TestAccountingReconcile - balances after emit, transfer, swap and industrial transfer match accounting records of batch events.
+// Against real environment without batch events ledger is built from balances of opened addresses, see accounting.Ledger.Collect
+func TestAccountingReconcile(t *testing.T) {
+	runner.Run(t, "reconcile balances with accounting records of batch events", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("Every balance built from accounting records of transactions of test is equal to balance in channel, amount of FIAT is conserved")
+		t.Tags("positive", "accounting")
+
+		var (
+			ctx    = context.Background()
+			ledger = accounting.NewLedger()
+
+			ledgerClient           *utils.Client
+			issuer, sender, target *fixtures.Identity
+			token                  *industrial.Token
+		)
+
+		t.WithNewStep("Create client tracking transactions into ledger and register users", func(sCtx provider.StepCtx) {
+			opts := []utils.ClientOption{utils.WithHook(ledger.Hook())}
+			if client.BatchEvents() {
+				opts = append(opts, utils.WithBatchEvents())
+			}
+			var err error
+			ledgerClient, err = utils.NewClientFromEnv(opts...)
+			sCtx.Require().NoError(err)
+
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			sender, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			target, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+		})
+
+		t.WithNewStep("Open balances of users and industrial balances of issuer used by previous tests", func(sCtx provider.StepCtx) {
+			sCtx.Require().NoError(ledger.Open(ctx, ledgerClient, "fiat", FiatName, sender.Address, target.Address))
+
+			var err error
+			token, err = industrial.Read(ctx, client, itSymbol)
+			sCtx.Require().NoError(err)
+			sCtx.Require().NotEmpty(token.Groups)
+			for _, group := range token.Groups {
+				err = ledger.Open(ctx, ledgerClient, itSymbol, token.Key(group.Id), issuer.Address, sender.Address)
+				sCtx.Require().NoError(err)
+			}
+		})
+
+		t.WithNewStep("Emit FIAT token to sender and transfer part of it to target", func(sCtx provider.StepCtx) {
+			signedArgs, err := issuer.Sign("fiat", "fiat", "emit", sender.Address, "10")
+			sCtx.Require().NoError(err)
+			_, err = ledgerClient.Invoke(ctx, "fiat", "emit", signedArgs...)
+			sCtx.Require().NoError(err)
+			requireBalance(ctx, sCtx, "fiat", sender.Address, amount.New(10))
+
+			signedArgs, err = sender.Sign("fiat", "fiat", "transfer", target.Address, "4", "")
+			sCtx.Require().NoError(err)
+			_, err = ledgerClient.Invoke(ctx, "fiat", "transfer", signedArgs...)
+			sCtx.Require().NoError(err)
+			requireBalance(ctx, sCtx, "fiat", sender.Address, amount.New(6))
+			requireBalance(ctx, sCtx, "fiat", target.Address, amount.New(4))
+		})
+
+		t.WithNewStep("Begin swap of FIAT token to cc and cancel it", func(sCtx provider.StepCtx) {
+			key, err := swap.NewKey()
+			sCtx.Require().NoError(err)
+			signedArgs, err := sender.Sign("fiat", "fiat", swap.BeginFn, FiatName, "CC", "1", swap.Hash(key))
+			sCtx.Require().NoError(err)
+			resp, err := ledgerClient.Invoke(ctx, "fiat", swap.BeginFn, signedArgs...)
+			sCtx.Require().NoError(err)
+			swapID := resp.TransactionID
+			requireBalance(ctx, sCtx, "fiat", sender.Address, amount.New(5))
+
+			signedArgs, err = sender.Sign("fiat", "fiat", swap.CancelFn, swapID)
+			sCtx.Require().NoError(err)
+			_, err = ledgerClient.Invoke(ctx, "fiat", swap.CancelFn, signedArgs...)
+			sCtx.Require().NoError(err)
+			requireBalance(ctx, sCtx, "fiat", sender.Address, amount.New(6))
+		})
+
+		t.WithNewStep("Initialize industrial token and transfer group to sender", func(sCtx provider.StepCtx) {
+			signedArgs, err := issuer.Sign(itSymbol, itSymbol, "initialize")
+			sCtx.Require().NoError(err)
+			_, err = ledgerClient.Invoke(ctx, itSymbol, "initialize", signedArgs...)
+			sCtx.Require().NoError(err)
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, itSymbol, utils.IndustrialBalanceOfFn, issuer.Address)
+			}, utils.IndustrialIssued())
+			sCtx.Require().NoError(err)
+
+			group := token.Groups[0].Id
+			signedArgs, err = issuer.Sign(itSymbol, itSymbol, "transferIndustrial", sender.Address, group, "3", "")
+			sCtx.Require().NoError(err)
+			_, err = ledgerClient.Invoke(ctx, itSymbol, "transferIndustrial", signedArgs...)
+			sCtx.Require().NoError(err)
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, itSymbol, utils.IndustrialBalanceOfFn, sender.Address)
+			}, utils.IndustrialAmountEquals(group, amount.New(3)))
+			sCtx.Require().NoError(err)
+		})
+
+		t.WithNewStep("Collect accounting records and reconcile balances", func(sCtx provider.StepCtx) {
+			sCtx.Require().NoError(ledger.Collect(ctx, ledgerClient))
+
+			sCtx.Assert().True(amount.Equal(amount.New(6), ledger.Expected("fiat", FiatName, sender.Address)))
+			sCtx.Assert().True(amount.Equal(amount.New(4), ledger.Expected("fiat", FiatName, target.Address)))
+			sCtx.Assert().True(amount.Equal(amount.New(3), ledger.Expected(itSymbol, token.Key(token.Groups[0].Id), sender.Address)))
+			sCtx.Assert().True(amount.Equal(amount.New(10), ledger.Total("fiat", FiatName)),
+				"transfer and cancelled swap don't conserve emitted FIAT: %s", amount.String(ledger.Total("fiat", FiatName)))
+
+			discrepancies, err := ledger.Reconcile(ctx, ledgerClient)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Empty(discrepancies)
+		})
+
+		t.WithNewStep("Record missing in channel is reported as discrepancy", func(sCtx provider.StepCtx) {
+			address, err := utils.ParseAddress(target.Address)
+			sCtx.Require().NoError(err)
+			sCtx.Require().NoError(ledger.AddRecords("fiat", &pb.AccountingRecord{
+				Token:     FiatName,
+				Recipient: address.Bytes(),
+				Amount:    amount.ToBytes(amount.New(1)),
+				Reason:    "emit",
+			}))
+
+			discrepancies, err := ledger.Reconcile(ctx, ledgerClient)
+			sCtx.Require().NoError(err)
+			sCtx.Require().Len(discrepancies, 1)
+			sCtx.Assert().Equal(target.Address, discrepancies[0].Address)
+			sCtx.Assert().True(amount.Equal(amount.New(5), discrepancies[0].Expected))
+			sCtx.Assert().True(amount.Equal(amount.New(4), discrepancies[0].Actual))
+		})
+
+		t.WithNewStep("Event with malformed record isn't added partly and can be added again", func(sCtx provider.StepCtx) {
+			senderAddress, err := utils.ParseAddress(sender.Address)
+			sCtx.Require().NoError(err)
+			targetAddress, err := utils.ParseAddress(target.Address)
+			sCtx.Require().NoError(err)
+
+			ledger := accounting.NewLedger()
+			record := &pb.AccountingRecord{
+				Token:     FiatName,
+				Sender:    senderAddress.Bytes(),
+				Recipient: targetAddress.Bytes(),
+				Amount:    amount.ToBytes(amount.New(1)),
+				Reason:    "transfer",
+			}
+			malformed := &pb.AccountingRecord{
+				Token:     FiatName,
+				Sender:    senderAddress.Bytes(),
+				Recipient: []byte{1, 2, 3},
+				Amount:    amount.ToBytes(amount.New(1)),
+				Reason:    "transfer",
+			}
+			event := &pb.BatchTxEvent{Id: []byte{1}, Accounting: []*pb.AccountingRecord{record, malformed}}
+			sCtx.Assert().Error(ledger.AddEvent("fiat", event))
+			sCtx.Assert().True(amount.IsZero(ledger.Expected("fiat", FiatName, sender.Address)))
+
+			event.Accounting = []*pb.AccountingRecord{record}
+			sCtx.Require().NoError(ledger.AddEvent("fiat", event))
+			sCtx.Require().NoError(ledger.AddEvent("fiat", event))
+			sCtx.Assert().True(amount.Equal(amount.New(-1), ledger.Expected("fiat", FiatName, sender.Address)))
+			sCtx.Assert().True(amount.Equal(amount.New(1), ledger.Expected("fiat", FiatName, target.Address)))
+		})
+	})
+}
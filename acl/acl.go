@@ -0,0 +1,160 @@
+// Package acl - rights of accounts and black and gray lists of acl chaincode of foundation library.
+// Right is granted to address for operation of role in chaincode of channel, queries return decoded protobuf
+package acl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// ChannelName - channel and chaincode of acl
+const ChannelName = "acl"
+
+const (
+	// AddRightsFn - method to grant right: channel, chaincode, role, operation, address
+	AddRightsFn = "addRights"
+	// RemoveRightsFn - method to revoke right: channel, chaincode, role, operation, address
+	RemoveRightsFn = "removeRights"
+	// GetAccountOperationRightFn - query of right: channel, chaincode, role, operation, address
+	GetAccountOperationRightFn = "getAccountOperationRight"
+	// GetAccountAllRightsFn - query of all rights of address
+	GetAccountAllRightsFn = "getAccountAllRights"
+	// GetOperationAllRightsFn - query of all rights to operation: channel, chaincode, role, operation
+	GetOperationAllRightsFn = "getOperationAllRights"
+	// GetAllAccountsFn - query of all accounts
+	GetAllAccountsFn = "getAllAccounts"
+	// GetAllOperationsFn - query of all operations
+	GetAllOperationsFn = "getAllOperations"
+)
+
+// Right - right of address to operation of role in chaincode of channel
+type Right struct {
+	Channel   string
+	Chaincode string
+	Role      string
+	Operation string
+	// Address - address in base58 check
+	Address string
+}
+
+func (r Right) String() string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", r.Channel, r.Chaincode, r.Role, r.Operation, r.Address)
+}
+
+func (r Right) args() []string {
+	return []string{r.Channel, r.Chaincode, r.Role, r.Operation, r.Address}
+}
+
+// FromProto - right of protobuf right, address is encoded to base58 check
+func FromProto(right *pb.Right) Right {
+	return Right{
+		Channel:   right.GetChannelName(),
+		Chaincode: right.GetChaincodeName(),
+		Role:      right.GetRoleName(),
+		Operation: right.GetOperationName(),
+		Address:   EncodeAddress(right.GetAddress()),
+	}
+}
+
+// EncodeAddress - address in base58 check, empty if address is empty or invalid, see utils.EncodeAddress
+func EncodeAddress(address *pb.Address) string {
+	encoded, err := utils.EncodeAddress(address.GetAddress())
+	if err != nil {
+		return ""
+	}
+	return encoded
+}
+
+// Client - client of acl chaincode
+type Client struct {
+	client *utils.Client
+}
+
+// NewClient - client of acl chaincode over hlf proxy client
+func NewClient(client *utils.Client) *Client {
+	return &Client{client: client}
+}
+
+// AddRights - grant right
+func (c *Client) AddRights(ctx context.Context, right Right) (*utils.Response, error) {
+	return c.client.Invoke(ctx, ChannelName, AddRightsFn, right.args()...)
+}
+
+// RemoveRights - revoke right
+func (c *Client) RemoveRights(ctx context.Context, right Right) (*utils.Response, error) {
+	return c.client.Invoke(ctx, ChannelName, RemoveRightsFn, right.args()...)
+}
+
+// HasRight - report whether right is granted
+func (c *Client) HasRight(ctx context.Context, right Right) (bool, error) {
+	haveRight := &pb.HaveRight{}
+	if err := c.query(ctx, haveRight, GetAccountOperationRightFn, right.args()...); err != nil {
+		return false, err
+	}
+	return haveRight.HaveRight, nil
+}
+
+// WaitRight - poll right until it is granted or revoked as expected, see utils.Eventually
+func (c *Client) WaitRight(ctx context.Context, right Right, expected bool) error {
+	_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+		return c.client.Query(ctx, ChannelName, GetAccountOperationRightFn, right.args()...)
+	}, func(resp *utils.Response) bool {
+		haveRight := &pb.HaveRight{}
+		return proto.Unmarshal(resp.Payload, haveRight) == nil && haveRight.HaveRight == expected
+	})
+	if err != nil {
+		return fmt.Errorf("wait right %s is %t: %w", right, expected, err)
+	}
+	return nil
+}
+
+// GetAccountAllRights - all rights of address in base58 check
+func (c *Client) GetAccountAllRights(ctx context.Context, address string) (*pb.AccountRights, error) {
+	rights := &pb.AccountRights{}
+	if err := c.query(ctx, rights, GetAccountAllRightsFn, address); err != nil {
+		return nil, err
+	}
+	return rights, nil
+}
+
+// GetOperationAllRights - rights of all addresses to operation of role in chaincode of channel
+func (c *Client) GetOperationAllRights(ctx context.Context, channel, chaincode, role, operation string) (*pb.OperationRights, error) {
+	rights := &pb.OperationRights{}
+	if err := c.query(ctx, rights, GetOperationAllRightsFn, channel, chaincode, role, operation); err != nil {
+		return nil, err
+	}
+	return rights, nil
+}
+
+// GetAllAccounts - addresses of all accounts
+func (c *Client) GetAllAccounts(ctx context.Context) (*pb.Accounts, error) {
+	accounts := &pb.Accounts{}
+	if err := c.query(ctx, accounts, GetAllAccountsFn); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// GetAllOperations - names of all operations
+func (c *Client) GetAllOperations(ctx context.Context) (*pb.Operations, error) {
+	operations := &pb.Operations{}
+	if err := c.query(ctx, operations, GetAllOperationsFn); err != nil {
+		return nil, err
+	}
+	return operations, nil
+}
+
+func (c *Client) query(ctx context.Context, msg proto.Message, fcn string, args ...string) error {
+	resp, err := c.client.Query(ctx, ChannelName, fcn, args...)
+	if err != nil {
+		return err
+	}
+	if err = proto.Unmarshal(resp.Payload, msg); err != nil {
+		return fmt.Errorf("%s %s: proto unmarshal: %w", ChannelName, fcn, err)
+	}
+	return nil
+}
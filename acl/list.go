@@ -0,0 +1,74 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+const (
+	// AddToListFn - method to add address to list: address, list type
+	AddToListFn = "addToList"
+	// DelFromListFn - method to remove address from list: address, list type
+	DelFromListFn = "delFromList"
+	// CheckAddressFn - query of account info and address of user by address
+	CheckAddressFn = "checkAddress"
+)
+
+// ListType - list of restricted accounts
+type ListType string
+
+const (
+	// BlackList - blacklisted address can't send or receive token, see utils.ErrBlacklisted
+	BlackList ListType = "black"
+	// GrayList - graylisted address can't sign transactions, see utils.ErrGraylisted
+	GrayList ListType = "gray"
+)
+
+// Listed - report whether account is in list
+func Listed(account *pb.AccountInfo, list ListType) bool {
+	switch list {
+	case BlackList:
+		return account.GetBlackListed()
+	case GrayList:
+		return account.GetGrayListed()
+	default:
+		return false
+	}
+}
+
+// AddToList - add address in base58 check to list
+func (c *Client) AddToList(ctx context.Context, address string, list ListType) (*utils.Response, error) {
+	return c.client.Invoke(ctx, ChannelName, AddToListFn, address, string(list))
+}
+
+// DelFromList - remove address in base58 check from list
+func (c *Client) DelFromList(ctx context.Context, address string, list ListType) (*utils.Response, error) {
+	return c.client.Invoke(ctx, ChannelName, DelFromListFn, address, string(list))
+}
+
+// CheckAddress - account info and signed address of user by address in base58 check
+func (c *Client) CheckAddress(ctx context.Context, address string) (*pb.AclResponse, error) {
+	resp := &pb.AclResponse{}
+	if err := c.query(ctx, resp, CheckAddressFn, address); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WaitListed - poll account of address until it is in list or out of it as expected, see utils.Eventually
+func (c *Client) WaitListed(ctx context.Context, address string, list ListType, expected bool) error {
+	_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+		return c.client.Query(ctx, ChannelName, CheckAddressFn, address)
+	}, func(resp *utils.Response) bool {
+		aclResp := &pb.AclResponse{}
+		return proto.Unmarshal(resp.Payload, aclResp) == nil && Listed(aclResp.Account, list) == expected
+	})
+	if err != nil {
+		return fmt.Errorf("wait address %s in %s list is %t: %w", address, list, expected, err)
+	}
+	return nil
+}
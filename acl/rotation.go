@@ -0,0 +1,59 @@
+package acl
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+	"golang.org/x/crypto/ed25519"
+)
+
+// CheckKeysFn - query of account info and signed address of user by public key in base58,
+// public keys of multisig are separated by '/'
+const CheckKeysFn = "checkKeys"
+
+// CheckKeys - account info and signed address of user by public key, see CheckKeysFn
+func (c *Client) CheckKeys(ctx context.Context, publicKey string) (*pb.AclResponse, error) {
+	resp := &pb.AclResponse{}
+	if err := c.query(ctx, resp, CheckKeysFn, publicKey); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ChangePublicKey - replace public key of user at address by new one signed by all validators,
+// address of user doesn't change, see utils.SignChangePublicKey
+func (c *Client) ChangePublicKey(ctx context.Context, validators []utils.Signer, address, reason string, reasonID int32, newPublicKey ed25519.PublicKey) (*utils.Response, error) {
+	nonce, err := validatorsNonce(validators)
+	if err != nil {
+		return nil, err
+	}
+	args, err := utils.SignChangePublicKey(validators, address, reason, reasonID, newPublicKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.Invoke(ctx, ChannelName, utils.ChangePublicKeyFn, args...)
+}
+
+// ChangeMultisigPublicKey - replace public key of multisig member by new one signed by all validators,
+// address and N of multisig don't change, see utils.SignChangeMultisigPublicKey
+func (c *Client) ChangeMultisigPublicKey(ctx context.Context, validators []utils.Signer, address string, oldPublicKey, newPublicKey ed25519.PublicKey, reason string, reasonID int32) (*utils.Response, error) {
+	nonce, err := validatorsNonce(validators)
+	if err != nil {
+		return nil, err
+	}
+	args, err := utils.SignChangeMultisigPublicKey(validators, address, oldPublicKey, newPublicKey, reason, reasonID, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.Invoke(ctx, ChannelName, utils.ChangeMultisigPublicKeyFn, args...)
+}
+
+// validatorsNonce - nonce of the first validator from utils.DefaultNonceSource
+func validatorsNonce(validators []utils.Signer) (string, error) {
+	if len(validators) == 0 {
+		return "", errors.New("no validators to sign")
+	}
+	return utils.DefaultNonceSource.Next(validators[0].PublicKey()), nil
+}
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/acl"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// TestAddress - addresses created by tests are parsed to bytes of acl responses and back, malformed addresses and keys are rejected
+func TestAddress(t *testing.T) {
+	runner.Run(t, "address and public key validation", func(t provider.T) {
+		t.Severity(allure.NORMAL)
+		t.Description("ParseAddress is inverse of address encoding of foundation library, bytes of protobuf messages are compared with base58 check addresses")
+		t.Tags("positive", "negative", "address")
+
+		var (
+			ctx       = context.Background()
+			aclClient = acl.NewClient(client)
+
+			user, member1, member2 *fixtures.Identity
+			multisig               *fixtures.Multisig
+		)
+
+		t.WithNewStep("Register user and multisig 2 of 2", func(sCtx provider.StepCtx) {
+			var err error
+			user, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			member1, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			member2, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			multisig, err = fixtures.NewMultisig(ctx, client, 2, member1, member2)
+			sCtx.Require().NoError(err)
+		})
+
+		t.WithNewStep("Addresses are equal to bytes of checkKeys responses", func(sCtx provider.StepCtx) {
+			for _, c := range []struct {
+				address, publicKey string
+			}{
+				{user.Address, user.PublicKeyBase58},
+				{multisig.Address, utils.MultisigKey(multisig.Policy)},
+			} {
+				address, err := utils.ParseAddress(c.address)
+				sCtx.Require().NoError(err)
+				sCtx.Assert().Equal(c.address, address.String())
+				sCtx.Assert().Len(address.Bytes(), utils.AddressLength)
+				sCtx.Assert().False(address.IsZero())
+
+				resp, err := aclClient.CheckKeys(ctx, c.publicKey)
+				sCtx.Require().NoError(err)
+				sCtx.Assert().True(address.EqualBytes(resp.Address.Address.Address))
+				sCtx.Assert().True(utils.AddressEqual(c.address, resp.Address.Address.Address))
+
+				fromBytes, err := utils.AddressFromBytes(resp.Address.Address.Address)
+				sCtx.Require().NoError(err)
+				sCtx.Assert().Equal(address, fromBytes)
+			}
+
+			sCtx.Assert().Equal(user.Address, utils.AddressOfPublicKey(user.PublicKey).String())
+			sCtx.Assert().False(utils.AddressEqual(user.Address, utils.AddressOfPublicKey(member1.PublicKey).Bytes()))
+		})
+
+		t.WithNewStep("Malformed addresses are rejected", func(sCtx provider.StepCtx) {
+			address, err := utils.ParseAddress(user.Address)
+			sCtx.Require().NoError(err)
+			raw := address.Bytes()
+
+			for name, malformed := range map[string]string{
+				"empty":          "",
+				"public key":     user.PublicKeyBase58,
+				"short":          base58.CheckEncode(raw[1:utils.AddressLength-1], raw[0]),
+				"wrong checksum": user.Address[:len(user.Address)-1] + flipBase58(user.Address[len(user.Address)-1]),
+				"not base58":     "0OIl" + user.Address[4:],
+			} {
+				err := utils.ValidateAddress(malformed)
+				sCtx.Assert().True(errors.Is(err, utils.ErrInvalidAddress), name)
+				sCtx.Assert().False(utils.AddressEqual(malformed, raw), name)
+			}
+
+			_, err = utils.AddressFromBytes(raw[1:])
+			sCtx.Assert().True(errors.Is(err, utils.ErrInvalidAddress))
+			encoded, err := utils.EncodeAddress(nil)
+			sCtx.Assert().NoError(err)
+			sCtx.Assert().Empty(encoded)
+		})
+
+		t.WithNewStep("Public keys in base58 are validated", func(sCtx provider.StepCtx) {
+			publicKey, err := utils.ValidatePublicKeyBase58(user.PublicKeyBase58)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Equal(user.PublicKey, publicKey)
+
+			for name, malformed := range map[string]string{
+				"empty":    "",
+				"address":  user.Address,
+				"multisig": utils.MultisigKey(multisig.Policy),
+				"short":    base58.Encode(user.PublicKey[1:]),
+			} {
+				_, err = utils.ValidatePublicKeyBase58(malformed)
+				sCtx.Assert().True(errors.Is(err, utils.ErrInvalidPublicKey), name)
+			}
+		})
+	})
+}
+
+// flipBase58 - another base58 character instead of c
+func flipBase58(c byte) string {
+	if c == '1' {
+		return "2"
+	}
+	return "1"
+}
@@ -2,9 +2,8 @@ package integration
 
 import (
 	"context"
-	"os"
+	"errors"
 	"testing"
-	"time"
 
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/ozontech/allure-go/pkg/allure"
@@ -29,17 +28,23 @@ func TestAclAddUser(t *testing.T) {
 		})
 
 		t.WithNewStep("Add user by invoking method `addUser` of chaincode `acl` with valid parameters", func(sCtx provider.StepCtx) {
-			_, err := utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-				os.Getenv(utils.EnvHlfProxyAuthToken),
-				"acl", "addUser", publicKey, "test", "testuser", "true")
+			_, err := client.Invoke(ctx, "acl", "addUser", publicKey, "test", "testuser", "true")
 			sCtx.Assert().NoError(err)
 		})
 
-		time.Sleep(utils.BatchTransactionTimeout)
 		t.WithNewStep("Check user is created by querying method `checkKeys` of chaincode `acl`", func(sCtx provider.StepCtx) {
-			_, err := utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-				os.Getenv(utils.EnvHlfProxyAuthToken), "acl", "checkKeys", publicKey)
+			_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, "acl", "checkKeys", publicKey)
+			}, nil)
 			sCtx.Assert().NoError(err)
 		})
+
+		t.WithNewStep("Add the same user again fails with known error of existing user", func(sCtx provider.StepCtx) {
+			_, err := client.Invoke(ctx, "acl", "addUser", publicKey, "test", "testuser", "true")
+			sCtx.Assert().True(errors.Is(err, utils.ErrUserAlreadyExists), "%v", err)
+
+			swapErr := &utils.TxError{Method: "swapBegin", Message: "swap " + publicKey + " already exists"}
+			sCtx.Assert().False(errors.Is(swapErr, utils.ErrUserAlreadyExists))
+		})
 	})
 }
@@ -0,0 +1,128 @@
+// Package amount - token amounts as *big.Int and their encodings used by chaincodes:
+// decimal strings in arguments, json strings in query payloads and big-endian bytes in proto messages.
+// Nil amount is treated as zero by every function
+package amount
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+const base = 10
+
+// New - amount from int64
+func New(v int64) *big.Int {
+	return big.NewInt(v)
+}
+
+// Parse - amount from decimal string, example "100"
+func Parse(s string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	return v, nil
+}
+
+// MustParse - like Parse but panics on invalid string, use it for constants
+func MustParse(s string) *big.Int {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String - decimal string of amount, argument of chaincode methods like emit and transfer
+func String(v *big.Int) string {
+	return orZero(v).String()
+}
+
+// FromBytes - amount from big-endian bytes of proto messages, example Swap.Amount or TokenFee.Fee
+func FromBytes(b []byte) *big.Int {
+	return new(big.Int).SetBytes(b)
+}
+
+// ToBytes - big-endian bytes of absolute value of amount for proto messages
+func ToBytes(v *big.Int) []byte {
+	return orZero(v).Bytes()
+}
+
+// FromPayload - amount from query payload, json string like balanceOf returns or json number
+func FromPayload(payload []byte) (*big.Int, error) {
+	var s string
+	if err := json.Unmarshal(payload, &s); err != nil {
+		var n json.Number
+		if err = json.Unmarshal(payload, &n); err != nil {
+			return nil, fmt.Errorf("invalid amount payload %s: %w", payload, err)
+		}
+		s = n.String()
+	}
+	return Parse(s)
+}
+
+// ToPayload - json string of amount like balanceOf returns
+func ToPayload(v *big.Int) []byte {
+	payload, _ := json.Marshal(String(v))
+	return payload
+}
+
+// FromPayloadMap - amounts from json object of strings like industrialBalanceOf returns, keys are groups
+func FromPayloadMap(payload []byte) (map[string]*big.Int, error) {
+	var raw map[string]string
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("invalid amounts payload %s: %w", payload, err)
+	}
+
+	result := make(map[string]*big.Int, len(raw))
+	for key, s := range raw {
+		v, err := Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("amount of %s: %w", key, err)
+		}
+		result[key] = v
+	}
+	return result, nil
+}
+
+// Add - sum of amounts
+func Add(values ...*big.Int) *big.Int {
+	sum := new(big.Int)
+	for _, v := range values {
+		sum.Add(sum, orZero(v))
+	}
+	return sum
+}
+
+// Sub - difference a - b
+func Sub(a, b *big.Int) *big.Int {
+	return new(big.Int).Sub(orZero(a), orZero(b))
+}
+
+// Mul - product a * b
+func Mul(a, b *big.Int) *big.Int {
+	return new(big.Int).Mul(orZero(a), orZero(b))
+}
+
+// Cmp - -1 if a < b, 0 if a == b, +1 if a > b
+func Cmp(a, b *big.Int) int {
+	return orZero(a).Cmp(orZero(b))
+}
+
+// Equal - a == b, use it in assertions instead of comparing *big.Int with reflection
+func Equal(a, b *big.Int) bool {
+	return Cmp(a, b) == 0
+}
+
+// IsZero - v == 0
+func IsZero(v *big.Int) bool {
+	return orZero(v).Sign() == 0
+}
+
+func orZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
@@ -0,0 +1,74 @@
+// Package cassette - recording and replaying of hlf proxy service traffic.
+// Recorder is http.RoundTripper which saves every '/invoke' and '/query' exchange into Cassette,
+// Replayer serves saved responses without network, see utils.WithHTTPClient
+package cassette
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tickets-dao/integration/utils"
+)
+
+// EnvCassettePath - file to record traffic of integration tests into, recording is disabled if it is empty
+const EnvCassettePath = "HLF_PROXY_CASSETTE"
+
+// Interaction - one exchange with hlf proxy service
+type Interaction struct {
+	// Path - path of request, '/invoke' or '/query'
+	Path    string         `json:"path"`
+	Request *utils.Request `json:"request"`
+	// StatusCode - http status of response
+	StatusCode int `json:"statusCode"`
+	// Body - raw body of response, utils.Response or utils.ResponseError in json
+	Body    string        `json:"body"`
+	Latency time.Duration `json:"latency"`
+}
+
+// Cassette - recorded interactions in order of requests
+type Cassette struct {
+	mu           sync.Mutex
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Load - read cassette from json file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+
+	c := &Cassette{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("json unmarshal: %w", err)
+	}
+	return c, nil
+}
+
+// Save - write cassette to json file, parent directories are created if necessary
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("json marshal: %w", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gomnd
+		return fmt.Errorf("create directory: %w", err)
+	}
+	if err = os.WriteFile(path, data, 0o644); err != nil { //nolint:gomnd,gosec
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
+
+func (c *Cassette) add(interaction *Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, interaction)
+}
@@ -0,0 +1,90 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tickets-dao/integration/utils"
+)
+
+// Recorder - http.RoundTripper which sends requests by next round tripper and records them into cassette
+type Recorder struct {
+	next     http.RoundTripper
+	cassette *Cassette
+}
+
+// NewRecorder - create recorder, nil next means http.DefaultTransport
+func NewRecorder(next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{
+		next:     next,
+		cassette: &Cassette{},
+	}
+}
+
+// Cassette - interactions recorded so far
+func (r *Recorder) Cassette() *Cassette {
+	return r.cassette
+}
+
+// Save - write recorded interactions to json file, see Cassette.Save
+func (r *Recorder) Save(path string) error {
+	return r.cassette.Save(path)
+}
+
+// RoundTrip - send request and record it with response, failed requests without response aren't recorded
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.cassette.add(&Interaction{
+		Path:       req.URL.Path,
+		Request:    request,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		Latency:    time.Since(start),
+	})
+
+	return resp, nil
+}
+
+// readRequest - decode body of request to hlf proxy service and restore it for next reader
+func readRequest(req *http.Request) (*utils.Request, error) {
+	if req.Body == nil {
+		return nil, fmt.Errorf("request %s has no body", req.URL.Path)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	request := &utils.Request{}
+	if err = json.Unmarshal(body, request); err != nil {
+		return nil, fmt.Errorf("json unmarshal request: %w", err)
+	}
+	return request, nil
+}
@@ -0,0 +1,241 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/tickets-dao/integration/utils"
+	"golang.org/x/crypto/ed25519"
+)
+
+// ErrInteractionNotFound - cassette has no unused interaction matching request
+var ErrInteractionNotFound = errors.New("interaction not found in cassette")
+
+// Matcher - reports whether recorded request can be replayed for actual request
+type Matcher func(recorded, actual *utils.Request) bool
+
+// MatchOption - configures matcher created by NewMatcher
+type MatchOption func(o *matchOptions)
+
+type matchOptions struct {
+	// ignored - positions of ignored arguments, negative position is counted from the end
+	ignored []int
+	// ignoredSigned - parts of signed arguments ignored only if both requests have wire layout of utils.SignedTx
+	// with the same number of signers
+	ignoredSigned signedParts
+}
+
+// signedParts - parts of signed arguments added by utils.Sign and utils.SignMultisigTx
+type signedParts struct {
+	nonce, publicKeys, signatures bool
+}
+
+// IgnoreArgs - don't compare arguments at positions, negative position is counted from the end,
+// example -1 is the last argument
+func IgnoreArgs(positions ...int) MatchOption {
+	return func(o *matchOptions) {
+		o.ignored = append(o.ignored, positions...)
+	}
+}
+
+// IgnoreNonceAndSignature - don't compare nonce and signatures added by utils.Sign or utils.SignMultisigTx,
+// they differ every run even for the same keys and arguments. Requests without signed layout are compared entirely
+func IgnoreNonceAndSignature() MatchOption {
+	return func(o *matchOptions) {
+		o.ignoredSigned.nonce, o.ignoredSigned.signatures = true, true
+	}
+}
+
+// IgnoreSignedArgs - don't compare nonce, public keys and signatures added by utils.Sign or utils.SignMultisigTx,
+// use it when keys are generated every run. Requests without signed layout are compared entirely
+func IgnoreSignedArgs() MatchOption {
+	return func(o *matchOptions) {
+		o.ignoredSigned = signedParts{nonce: true, publicKeys: true, signatures: true}
+	}
+}
+
+// nonceMinIndex - nonce follows empty slot, chaincode and channel in wire slice of utils.SignedTx
+const nonceMinIndex = 3
+
+// signersCount - number of public keys in the end of wire slice of utils.SignedTx followed by the same number
+// of signatures, 0 if arguments have no signed layout. Nonce precedes public keys
+func signersCount(args [][]byte) int {
+	n := len(args)
+	if n == 0 || len(args[0]) != 0 {
+		return 0
+	}
+	for k := 1; n-2*k-1 >= nonceMinIndex; k++ {
+		ok := true
+		for _, publicKey := range args[n-2*k : n-k] {
+			if len(base58.Decode(string(publicKey))) != ed25519.PublicKeySize {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return k
+		}
+	}
+	return 0
+}
+
+// positions - positions of signed parts in wire slice of n arguments with k signers
+func (p signedParts) positions(n, k int) []int {
+	var positions []int
+	if p.nonce {
+		positions = append(positions, n-2*k-1)
+	}
+	for i := 0; i < k; i++ {
+		if p.publicKeys {
+			positions = append(positions, n-2*k+i)
+		}
+		if p.signatures {
+			positions = append(positions, n-k+i)
+		}
+	}
+	return positions
+}
+
+// NewMatcher - matcher which compares chaincode, method, number of arguments and arguments except ignored ones
+func NewMatcher(opts ...MatchOption) Matcher {
+	o := &matchOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(recorded, actual *utils.Request) bool {
+		if recorded.ChaincodeID != actual.ChaincodeID || recorded.Fcn != actual.Fcn || len(recorded.Args) != len(actual.Args) {
+			return false
+		}
+
+		positions := o.ignored
+		if o.ignoredSigned != (signedParts{}) {
+			if k := signersCount(actual.Args); k != 0 && k == signersCount(recorded.Args) {
+				positions = append(append([]int{}, positions...), o.ignoredSigned.positions(len(actual.Args), k)...)
+			}
+		}
+		ignored := make(map[int]bool, len(positions))
+		for _, position := range positions {
+			if position < 0 {
+				position += len(actual.Args)
+			}
+			ignored[position] = true
+		}
+		for i := range actual.Args {
+			if !ignored[i] && !bytes.Equal(recorded.Args[i], actual.Args[i]) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// ReplayerOption - configures Replayer
+type ReplayerOption func(r *Replayer)
+
+// WithMatcher - override default matcher which compares all arguments
+func WithMatcher(matcher Matcher) ReplayerOption {
+	return func(r *Replayer) {
+		r.matcher = matcher
+	}
+}
+
+// WithLatency - wait recorded latency before every response
+func WithLatency() ReplayerOption {
+	return func(r *Replayer) {
+		r.latency = true
+	}
+}
+
+// Replayer - http.RoundTripper which serves responses from cassette without network.
+// Every interaction is used once in order of recording, so repeated queries get responses in the same order
+type Replayer struct {
+	matcher Matcher
+	latency bool
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayer - create replayer of cassette
+func NewReplayer(cassette *Cassette, opts ...ReplayerOption) *Replayer {
+	r := &Replayer{
+		matcher:  NewMatcher(),
+		cassette: cassette,
+		used:     make([]bool, len(cassette.Interactions)),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Unused - interactions of cassette which weren't replayed
+func (r *Replayer) Unused() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []*Interaction
+	for i, interaction := range r.cassette.Interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// RoundTrip - respond with the first unused interaction matching request, ErrInteractionNotFound otherwise
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction, err := r.next(path.Base(req.URL.Path), request)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.latency {
+		select {
+		case <-time.After(interaction.Latency):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(interaction.Body)),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Replayer) next(requestType string, request *utils.Request) (*Interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || path.Base(interaction.Path) != requestType || !r.matcher(interaction.Request, request) {
+			continue
+		}
+		r.used[i] = true
+		return interaction, nil
+	}
+	return nil, fmt.Errorf("%s %s %s: %w", requestType, request.ChaincodeID, request.Fcn, ErrInteractionNotFound)
+}
@@ -0,0 +1,132 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/cassette"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// TestCassetteReplay - record emission to user into cassette and replay the same scenario without hlf proxy service
+func TestCassetteReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	runner.Run(t, "Record and replay emission of `fiat` token", func(t provider.T) {
+		t.Severity(allure.NORMAL)
+		t.Description("Testing that recorded traffic of hlf proxy service is replayed with fresh nonces and signatures")
+		t.Tags("positive", "cassette")
+
+		var (
+			ctx = context.Background()
+
+			issuer, user *fixtures.Identity
+		)
+
+		// emit - register issuer and user with existing keys, emit token to user and wait for balance
+		emit := func(sCtx provider.StepCtx, client *utils.Client) {
+			var err error
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+
+			opts := []fixtures.Option{}
+			if user != nil {
+				opts = append(opts, fixtures.WithPrivateKey(user.PrivateKey))
+			}
+			user, err = fixtures.NewUser(ctx, client, opts...)
+			sCtx.Require().NoError(err)
+
+			signedEmitArgs, err := issuer.Sign("fiat", "fiat", "emit", user.Address, "1")
+			sCtx.Require().NoError(err)
+
+			_, err = client.Invoke(ctx, "fiat", "emit", signedEmitArgs...)
+			sCtx.Require().NoError(err)
+
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, "fiat", "balanceOf", user.Address)
+			}, utils.AmountEquals(amount.New(1)))
+			sCtx.Require().NoError(err)
+		}
+
+		t.WithNewStep("Record emission into cassette", func(sCtx provider.StepCtx) {
+			recorder := cassette.NewRecorder(nil)
+			recordClient, err := utils.NewClientFromEnv(utils.WithHTTPClient(&http.Client{Transport: recorder}))
+			sCtx.Require().NoError(err)
+
+			emit(sCtx, recordClient)
+
+			sCtx.Assert().NotEmpty(recorder.Cassette().Interactions)
+			sCtx.Require().NoError(recorder.Save(path))
+		})
+
+		t.WithNewStep("Replay emission from cassette", func(sCtx provider.StepCtx) {
+			recorded, err := cassette.Load(path)
+			sCtx.Require().NoError(err)
+
+			replayer := cassette.NewReplayer(recorded, cassette.WithMatcher(cassette.NewMatcher(cassette.IgnoreNonceAndSignature())))
+			replayClient, err := utils.NewClient("http://replay.invalid", utils.WithHTTPClient(&http.Client{Transport: replayer}))
+			sCtx.Require().NoError(err)
+
+			emit(sCtx, replayClient)
+			sCtx.Assert().Empty(replayer.Unused())
+
+			_, err = replayClient.Query(ctx, "fiat", "balanceOf", issuer.Address)
+			sCtx.Assert().True(errors.Is(err, cassette.ErrInteractionNotFound))
+		})
+
+		t.WithNewStep("Query of balance of other address isn't matched by recorded query", func(sCtx provider.StepCtx) {
+			recorded, err := cassette.Load(path)
+			sCtx.Require().NoError(err)
+
+			replayer := cassette.NewReplayer(recorded, cassette.WithMatcher(cassette.NewMatcher(cassette.IgnoreNonceAndSignature())))
+			replayClient, err := utils.NewClient("http://replay.invalid", utils.WithHTTPClient(&http.Client{Transport: replayer}))
+			sCtx.Require().NoError(err)
+
+			_, err = replayClient.Query(ctx, "fiat", "balanceOf", issuer.Address)
+			sCtx.Assert().True(errors.Is(err, cassette.ErrInteractionNotFound))
+			_, err = replayClient.Query(ctx, "acl", "checkKeys", user.Address)
+			sCtx.Assert().True(errors.Is(err, cassette.ErrInteractionNotFound))
+
+			resp, err := replayClient.Query(ctx, "fiat", "balanceOf", user.Address)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().NotEmpty(resp.Payload)
+		})
+
+		t.WithNewStep("Invoke of multisig is replayed with fresh nonce and signatures of members", func(sCtx provider.StepCtx) {
+			member1, err := fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			member2, err := fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			multisig, err := fixtures.NewMultisig(ctx, client, 2, member1, member2)
+			sCtx.Require().NoError(err)
+			members := []*fixtures.Identity{member1, member2}
+
+			recorder := cassette.NewRecorder(nil)
+			recordClient, err := utils.NewClientFromEnv(utils.WithHTTPClient(&http.Client{Transport: recorder}))
+			sCtx.Require().NoError(err)
+			recordedArgs, err := multisig.Sign(members, "fiat", "fiat", "transfer", user.Address, "1", "")
+			sCtx.Require().NoError(err)
+			_, err = recordClient.Invoke(ctx, "fiat", "transfer", recordedArgs...)
+			sCtx.Require().NoError(err)
+
+			replayer := cassette.NewReplayer(recorder.Cassette(), cassette.WithMatcher(cassette.NewMatcher(cassette.IgnoreNonceAndSignature())))
+			replayClient, err := utils.NewClient("http://replay.invalid", utils.WithHTTPClient(&http.Client{Transport: replayer}))
+			sCtx.Require().NoError(err)
+
+			signedArgs, err := multisig.Sign(members, "fiat", "fiat", "transfer", user.Address, "1", "")
+			sCtx.Require().NoError(err)
+			sCtx.Require().NotEqual(recordedArgs, signedArgs)
+			_, err = replayClient.Invoke(ctx, "fiat", "transfer", signedArgs...)
+			sCtx.Assert().NoError(err)
+			sCtx.Assert().Empty(replayer.Unused())
+		})
+	})
+}
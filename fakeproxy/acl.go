@@ -0,0 +1,461 @@
+package fakeproxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang/protobuf/proto"
+	pb "github.com/tickets-dao/integration/proto"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+const aclName = "acl"
+
+const (
+	blackList = "black"
+	grayList  = "gray"
+)
+
+type aclUser struct {
+	// publicKey - public key in base58, sorted public keys separated by '/' for multisig
+	publicKey    string
+	address      []byte
+	kycHash      string
+	userID       string
+	isIndustrial bool
+	// policy - signature policy of multisig, nil for ordinary user
+	policy *pb.SignaturePolicy
+	// grayListed - user can't sign transactions
+	grayListed bool
+	// blackListed - user can't send and receive token
+	blackListed bool
+	// signedTx - changePublicKey signed by validators which set current public key, empty for original key
+	signedTx []string
+	reason   string
+	reasonID int32
+}
+
+type aclChaincode struct {
+	// users - registered users by base58 public key
+	users map[string]*aclUser
+	// rights - args of granted rights by rightKey: channel, chaincode, role, operation, address
+	rights map[string][]string
+	// validators - public keys signing replacement of user keys
+	validators []ed25519.PublicKey
+}
+
+func newACLChaincode() *aclChaincode {
+	return &aclChaincode{
+		users:  make(map[string]*aclUser),
+		rights: make(map[string][]string),
+	}
+}
+
+func (acl *aclChaincode) invoke(fcn string, args []string) ([]byte, error) {
+	switch fcn {
+	case "addUser":
+		return nil, acl.addUser(args)
+	case "addMultisig":
+		return nil, acl.addMultisig(args)
+	case "addRights":
+		return nil, acl.setRight(args, true)
+	case "removeRights":
+		return nil, acl.setRight(args, false)
+	case "addToList":
+		return nil, acl.setListed(args, true)
+	case "delFromList":
+		return nil, acl.setListed(args, false)
+	case "changePublicKey":
+		return nil, acl.changePublicKey(args)
+	case "changeMultisigPublicKey":
+		return nil, acl.changeMultisigPublicKey(args)
+	default:
+		return nil, fmt.Errorf("invoke method %s not found in chaincode %s", fcn, aclName)
+	}
+}
+
+func (acl *aclChaincode) query(fcn string, args []string) ([]byte, error) {
+	switch fcn {
+	case "checkKeys":
+		return acl.checkKeys(args)
+	case "checkAddress":
+		return acl.checkAddress(args)
+	case "getAccountOperationRight":
+		return acl.getAccountOperationRight(args)
+	case "getAccountAllRights":
+		return acl.getAccountAllRights(args)
+	case "getOperationAllRights":
+		return acl.getOperationAllRights(args)
+	case "getAllAccounts":
+		return acl.getAllAccounts(args)
+	case "getAllOperations":
+		return acl.getAllOperations(args)
+	default:
+		return nil, fmt.Errorf("query method %s not found in chaincode %s", fcn, aclName)
+	}
+}
+
+// addUser - args: public key in base58, kyc hash, user id, is industrial
+func (acl *aclChaincode) addUser(args []string) error {
+	const argsLen = 4
+	if len(args) != argsLen {
+		return fmt.Errorf("incorrect number of arguments: %d, expected %d", len(args), argsLen)
+	}
+
+	publicKey, kycHash, userID := args[0], args[1], args[2]
+	decoded := base58.Decode(publicKey)
+	if len(decoded) == 0 {
+		return fmt.Errorf("failed base58 decoding of key %s", publicKey)
+	}
+	if kycHash == "" {
+		return errors.New("empty kyc hash")
+	}
+	if userID == "" {
+		return errors.New("empty userID")
+	}
+	isIndustrial, err := strconv.ParseBool(args[3])
+	if err != nil {
+		return fmt.Errorf("failed to parse isIndustrial: %w", err)
+	}
+
+	if _, ok := acl.users[publicKey]; ok {
+		return fmt.Errorf("the user associated with the public key %s already exists", publicKey)
+	}
+
+	address := sha3.Sum256(decoded)
+	acl.users[publicKey] = &aclUser{
+		publicKey:    publicKey,
+		address:      address[:],
+		kycHash:      kycHash,
+		userID:       userID,
+		isIndustrial: isIndustrial,
+	}
+
+	return nil
+}
+
+// checkKeys - args: public key in base58
+func (acl *aclChaincode) checkKeys(args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(args))
+	}
+
+	user, err := acl.user(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(user.aclResponse())
+}
+
+// checkAddress - args: address in base58 check
+func (acl *aclChaincode) checkAddress(args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(args))
+	}
+
+	user, err := acl.userByAddress(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(user.aclResponse())
+}
+
+// setListed - args: address in base58 check, list type black or gray
+func (acl *aclChaincode) setListed(args []string, listed bool) error {
+	const argsLen = 2
+	if len(args) != argsLen {
+		return fmt.Errorf("incorrect number of arguments: %d, expected %d", len(args), argsLen)
+	}
+
+	user, err := acl.userByAddress(args[0])
+	if err != nil {
+		return err
+	}
+	switch args[1] {
+	case blackList:
+		user.blackListed = listed
+	case grayList:
+		user.grayListed = listed
+	default:
+		return fmt.Errorf("unknown list type %s", args[1])
+	}
+	return nil
+}
+
+// addMultisig - args: empty slot, chaincode, channel, N, nonce, M public keys in base58, M signatures in base58.
+// Every member of multisig must sign, N of M signatures are required by transactions of multisig address later
+func (acl *aclChaincode) addMultisig(args []string) error {
+	const serviceLen = 5
+	if len(args) < serviceLen+2 || (len(args)-serviceLen)%2 != 0 {
+		return fmt.Errorf("incorrect number of arguments: %d", len(args))
+	}
+	if args[1] != aclName || args[2] != aclName {
+		return fmt.Errorf("incorrect chaincode %s or channel %s, expected %s", args[1], args[2], aclName)
+	}
+
+	m := (len(args) - serviceLen) / 2
+	n, err := strconv.Atoi(args[3])
+	if err != nil {
+		return fmt.Errorf("failed to parse N: %w", err)
+	}
+	if n <= 0 || n > m {
+		return fmt.Errorf("incorrect N %d of %d public keys", n, m)
+	}
+
+	publicKeys, sigs := args[serviceLen:serviceLen+m], args[serviceLen+m:]
+	policy := &pb.SignaturePolicy{N: uint32(n)}
+	for _, publicKey := range publicKeys {
+		decoded := base58.Decode(publicKey)
+		if len(decoded) != ed25519.PublicKeySize {
+			return fmt.Errorf("incorrect public key %s", publicKey)
+		}
+		policy.PubKeys = append(policy.PubKeys, decoded)
+	}
+	if !sort.SliceIsSorted(policy.PubKeys, func(i, j int) bool { return bytes.Compare(policy.PubKeys[i], policy.PubKeys[j]) < 0 }) {
+		return errors.New("public keys must be sorted")
+	}
+
+	message := sha3.Sum256([]byte("addMultisig" + strings.Join(args[:serviceLen+m], "")))
+	for i, sig := range sigs {
+		if !ed25519.Verify(policy.PubKeys[i], message[:], base58.Decode(sig)) {
+			return fmt.Errorf("incorrect signature of public key %s", publicKeys[i])
+		}
+	}
+
+	key := strings.Join(publicKeys, "/")
+	if _, ok := acl.users[key]; ok {
+		return fmt.Errorf("multisig with public keys %s already exists", key)
+	}
+
+	address := sha3.Sum256(bytes.Join(policy.PubKeys, nil))
+	acl.users[key] = &aclUser{
+		publicKey: key,
+		address:   address[:],
+		kycHash:   "multisig",
+		userID:    "multisig",
+		policy:    policy,
+	}
+
+	return nil
+}
+
+// setRight - args: channel, chaincode, role, operation, address
+func (acl *aclChaincode) setRight(args []string, haveRight bool) error {
+	key, err := rightKey(args)
+	if err != nil {
+		return err
+	}
+	if _, err = decodeAddress(args[4]); err != nil {
+		return err
+	}
+
+	if haveRight {
+		acl.rights[key] = args
+	} else {
+		delete(acl.rights, key)
+	}
+	return nil
+}
+
+// getAccountOperationRight - args: channel, chaincode, role, operation, address
+func (acl *aclChaincode) getAccountOperationRight(args []string) ([]byte, error) {
+	key, err := rightKey(args)
+	if err != nil {
+		return nil, err
+	}
+
+	_, ok := acl.rights[key]
+	return proto.Marshal(&pb.HaveRight{HaveRight: ok})
+}
+
+// getAccountAllRights - args: address. Rights of address sorted by channel, chaincode, role and operation
+func (acl *aclChaincode) getAccountAllRights(args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(args))
+	}
+	address, err := acl.address(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	rights, err := acl.filterRights(func(right []string) bool { return right[4] == args[0] })
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&pb.AccountRights{Address: address, Rights: rights})
+}
+
+// getOperationAllRights - args: channel, chaincode, role, operation. Rights of all addresses to operation
+func (acl *aclChaincode) getOperationAllRights(args []string) ([]byte, error) {
+	const argsLen = 4
+	if len(args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(args), argsLen)
+	}
+
+	rights, err := acl.filterRights(func(right []string) bool {
+		return strings.Join(right[:argsLen], "/") == strings.Join(args, "/")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&pb.OperationRights{OperationName: args[3], Rights: rights})
+}
+
+// getAllAccounts - no args. Addresses of registered users sorted by address
+func (acl *aclChaincode) getAllAccounts(args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 0", len(args))
+	}
+
+	accounts := &pb.Accounts{}
+	for _, user := range acl.users {
+		accounts.Addresses = append(accounts.Addresses, user.pbAddress())
+	}
+	sort.Slice(accounts.Addresses, func(i, j int) bool {
+		return bytes.Compare(accounts.Addresses[i].Address, accounts.Addresses[j].Address) < 0
+	})
+	return proto.Marshal(accounts)
+}
+
+// getAllOperations - no args. Sorted names of operations with at least one granted right
+func (acl *aclChaincode) getAllOperations(args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 0", len(args))
+	}
+
+	names := make(map[string]struct{})
+	for _, right := range acl.rights {
+		names[right[3]] = struct{}{}
+	}
+	operations := &pb.Operations{}
+	for name := range names {
+		operations.Operations = append(operations.Operations, name)
+	}
+	sort.Strings(operations.Operations)
+	return proto.Marshal(operations)
+}
+
+// filterRights - granted rights matching filter sorted by key
+func (acl *aclChaincode) filterRights(filter func(right []string) bool) ([]*pb.Right, error) {
+	keys := make([]string, 0, len(acl.rights))
+	for key, right := range acl.rights {
+		if filter(right) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	rights := make([]*pb.Right, 0, len(keys))
+	for _, key := range keys {
+		right := acl.rights[key]
+		address, err := acl.address(right[4])
+		if err != nil {
+			return nil, err
+		}
+		rights = append(rights, &pb.Right{
+			ChannelName:   right[0],
+			ChaincodeName: right[1],
+			RoleName:      right[2],
+			OperationName: right[3],
+			Address:       address,
+			HaveRight:     &pb.HaveRight{HaveRight: true},
+		})
+	}
+	return rights, nil
+}
+
+// address - address of registered user by base58 check address, only address bytes if user isn't registered
+func (acl *aclChaincode) address(address string) (*pb.Address, error) {
+	if user, err := acl.userByAddress(address); err == nil {
+		return user.pbAddress(), nil
+	}
+	decoded, err := decodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Address{Address: decoded}, nil
+}
+
+// userByAddress - find user by address in base58 check
+func (acl *aclChaincode) userByAddress(address string) (*aclUser, error) {
+	decoded, err := decodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range acl.users {
+		if bytes.Equal(user.address, decoded) {
+			return user, nil
+		}
+	}
+	return nil, fmt.Errorf("no address %s in acl", address)
+}
+
+func (user *aclUser) aclResponse() *pb.AclResponse {
+	return &pb.AclResponse{
+		Account: &pb.AccountInfo{
+			KycHash:     user.kycHash,
+			GrayListed:  user.grayListed,
+			BlackListed: user.blackListed,
+		},
+		Address: &pb.SignedAddress{
+			Address:         user.pbAddress(),
+			SignedTx:        user.signedTx,
+			SignaturePolicy: user.policy,
+			Reason:          user.reason,
+			ReasonId:        user.reasonID,
+		},
+	}
+}
+
+func (user *aclUser) pbAddress() *pb.Address {
+	return &pb.Address{
+		UserID:       user.userID,
+		Address:      user.address,
+		IsIndustrial: user.isIndustrial,
+		IsMultisig:   user.policy != nil,
+	}
+}
+
+// user - find user by public key in base58, multisig is found by its public keys separated by '/' in any order
+func (acl *aclChaincode) user(publicKey string) (*aclUser, error) {
+	if keys := strings.Split(publicKey, "/"); len(keys) > 1 {
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(base58.Decode(keys[i]), base58.Decode(keys[j])) < 0 })
+		publicKey = strings.Join(keys, "/")
+	}
+
+	user, ok := acl.users[publicKey]
+	if !ok {
+		return nil, fmt.Errorf("no public key %s in acl", publicKey)
+	}
+	return user, nil
+}
+
+func rightKey(args []string) (string, error) {
+	const argsLen = 5
+	if len(args) != argsLen {
+		return "", fmt.Errorf("incorrect number of arguments: %d, expected %d", len(args), argsLen)
+	}
+	for _, arg := range args {
+		if arg == "" {
+			return "", errors.New("empty argument")
+		}
+	}
+	return strings.Join(args, "/"), nil
+}
+
+// checkBlackList - none of addresses in base58 check is blacklisted, unknown address isn't blacklisted
+func (acl *aclChaincode) checkBlackList(addresses ...string) error {
+	for _, address := range addresses {
+		if user, err := acl.userByAddress(address); err == nil && user.blackListed {
+			return fmt.Errorf("address %s is blacklisted", address)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package fakeproxy
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/tickets-dao/integration/proto"
+)
+
+// batchExecuteFn - method of token chaincode invoked by robot with pb.Batch
+const batchExecuteFn = "batchExecute"
+
+// batchExecute - args: pb.Batch. Executes pending transactions, saves swaps and multi swaps started in other channels,
+// completes swaps of this channel by swap keys. Returns pb.BatchResponse with swaps started by transactions of batch
+func (s *Server) batchExecute(cc *tokenChaincode, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(args))
+	}
+	batch := &pb.Batch{}
+	if err := proto.Unmarshal([]byte(args[0]), batch); err != nil {
+		return nil, fmt.Errorf("unmarshal batch: %w", err)
+	}
+
+	resp := &pb.BatchResponse{}
+	for _, id := range batch.TxIDs {
+		txID := hex.EncodeToString(id)
+		tx, ok := cc.pending[txID]
+		if !ok {
+			resp.TxResponses = append(resp.TxResponses, &pb.TxResponse{
+				Id:    id,
+				Error: &pb.ResponseError{Code: http.StatusNotFound, Error: fmt.Sprintf("transaction %s not found", txID)},
+			})
+			continue
+		}
+		delete(cc.pending, txID)
+
+		event := s.executeBatchTx(cc, tx, cc.methods[tx.method])
+		resp.TxResponses = append(resp.TxResponses, &pb.TxResponse{
+			Id:     id,
+			Method: tx.method,
+			Error:  event.Error,
+		})
+	}
+
+	for _, swap := range batch.Swaps {
+		resp.SwapResponses = append(resp.SwapResponses, swapResponse(swap.Id, cc.robotSwap(swap)))
+	}
+	for _, swap := range batch.MultiSwaps {
+		resp.SwapResponses = append(resp.SwapResponses, swapResponse(swap.Id, cc.robotMultiSwap(swap)))
+	}
+	for _, key := range batch.Keys {
+		resp.SwapKeyResponses = append(resp.SwapKeyResponses, swapResponse(key.Id, cc.robotSwapDone(key)))
+	}
+	for _, key := range batch.MultiSwapsKeys {
+		resp.SwapKeyResponses = append(resp.SwapKeyResponses, swapResponse(key.Id, cc.robotMultiSwapDone(key)))
+	}
+
+	resp.CreatedSwaps, cc.createdSwaps = cc.createdSwaps, nil
+	resp.CreatedMultiSwap, cc.createdMultiSwaps = cc.createdMultiSwaps, nil
+
+	return proto.Marshal(resp)
+}
+
+// robotSwap - save swap started in other channel and directed to this channel
+func (cc *tokenChaincode) robotSwap(swap *pb.Swap) error {
+	id := hex.EncodeToString(swap.Id)
+	if swap.To != cc.symbol {
+		return fmt.Errorf("swap %s is directed to %s, not to %s", id, swap.To, cc.symbol)
+	}
+	if _, ok := cc.swaps[id]; ok {
+		return fmt.Errorf("swap %s already exists", id)
+	}
+	cc.swaps[id] = proto.Clone(swap).(*pb.Swap)
+	return nil
+}
+
+// robotMultiSwap - save multi swap started in other channel and directed to this channel
+func (cc *tokenChaincode) robotMultiSwap(swap *pb.MultiSwap) error {
+	id := hex.EncodeToString(swap.Id)
+	if swap.To != cc.symbol {
+		return fmt.Errorf("multiswap %s is directed to %s, not to %s", id, swap.To, cc.symbol)
+	}
+	if _, ok := cc.multiSwaps[id]; ok {
+		return fmt.Errorf("multiswap %s already exists", id)
+	}
+	cc.multiSwaps[id] = proto.Clone(swap).(*pb.MultiSwap)
+	return nil
+}
+
+// robotSwapDone - remove swap started in this channel and completed by swapDone in destination channel
+func (cc *tokenChaincode) robotSwapDone(key *pb.SwapKey) error {
+	id := hex.EncodeToString(key.Id)
+	swap, ok := cc.swaps[id]
+	if !ok || swap.From != cc.symbol {
+		return fmt.Errorf("swap doesn't exist by key %s", id)
+	}
+	if err := checkSwapKey(swap.Hash, key.Key); err != nil {
+		return err
+	}
+	delete(cc.swaps, id)
+	return nil
+}
+
+// robotMultiSwapDone - remove multi swap started in this channel and completed by multiSwapDone in destination channel
+func (cc *tokenChaincode) robotMultiSwapDone(key *pb.SwapKey) error {
+	id := hex.EncodeToString(key.Id)
+	swap, ok := cc.multiSwaps[id]
+	if !ok || swap.From != cc.symbol {
+		return fmt.Errorf("multiswap doesn't exist by key %s", id)
+	}
+	if err := checkSwapKey(swap.Hash, key.Key); err != nil {
+		return err
+	}
+	delete(cc.multiSwaps, id)
+	return nil
+}
+
+func swapResponse(id []byte, err error) *pb.SwapResponse {
+	resp := &pb.SwapResponse{Id: id}
+	if err != nil {
+		resp.Error = &pb.ResponseError{Code: http.StatusInternalServerError, Error: err.Error()}
+	}
+	return resp
+}
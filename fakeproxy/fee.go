@@ -0,0 +1,117 @@
+package fakeproxy
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	pb "github.com/tickets-dao/integration/proto"
+)
+
+// feeDecimals - fee is percentage with 8 decimals, 100000000 is 100%
+const feeDecimals = 8
+
+// metadataFee - fee in metadata of token chaincode
+type metadataFee struct {
+	Address  string   `json:"address"`
+	Currency string   `json:"currency"`
+	Fee      *big.Int `json:"fee"`
+	Floor    *big.Int `json:"floor"`
+	Cap      *big.Int `json:"cap"`
+}
+
+// setFee - args: currency, fee, floor, cap. Only issuer can set fee, currency must be symbol of token.
+// Fee is percentage with feeDecimals, zero cap means no upper limit
+func (s *Server) setFee(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 4
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	if err := s.checkIssuer(tx); err != nil {
+		return nil, err
+	}
+
+	currency := strings.ToUpper(tx.args[0])
+	if currency != cc.symbol {
+		return nil, fmt.Errorf("incorrect fee currency %s, expected %s", currency, cc.symbol)
+	}
+	values := make([]*big.Int, argsLen-1)
+	for i, arg := range tx.args[1:] {
+		value, ok := new(big.Int).SetString(arg, 10)
+		if !ok || value.Sign() < 0 {
+			return nil, fmt.Errorf("invalid fee value %s", arg)
+		}
+		values[i] = value
+	}
+	fee, floor, feeCap := values[0], values[1], values[2]
+	if fee.Cmp(hundredPercent()) > 0 {
+		return nil, errors.New("fee should be equal or less than 100%")
+	}
+	if feeCap.Sign() > 0 && floor.Cmp(feeCap) > 0 {
+		return nil, errors.New("incorrect limits: floor is greater than cap")
+	}
+
+	cc.fee = &pb.TokenFee{
+		Currency: currency,
+		Fee:      fee.Bytes(),
+		Floor:    floor.Bytes(),
+		Cap:      feeCap.Bytes(),
+	}
+	return nil, nil
+}
+
+// setFeeAddress - args: address. Only issuer can set address receiving fee
+func (s *Server) setFeeAddress(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	if len(tx.args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(tx.args))
+	}
+	if err := s.checkIssuer(tx); err != nil {
+		return nil, err
+	}
+	address, err := decodeAddress(tx.args[0])
+	if err != nil {
+		return nil, err
+	}
+	cc.feeAddress = address
+	return nil, nil
+}
+
+// transferFee - fee of transfer amount: percentage of amount limited by floor and cap, zero if fee isn't set
+func (cc *tokenChaincode) transferFee(amount *big.Int) *big.Int {
+	if cc.fee == nil {
+		return new(big.Int)
+	}
+
+	fee := new(big.Int).Mul(amount, new(big.Int).SetBytes(cc.fee.Fee))
+	fee.Div(fee, hundredPercent())
+	if feeCap := new(big.Int).SetBytes(cc.fee.Cap); feeCap.Sign() > 0 && fee.Cmp(feeCap) > 0 {
+		fee = feeCap
+	}
+	if floor := new(big.Int).SetBytes(cc.fee.Floor); fee.Cmp(floor) < 0 {
+		fee = floor
+	}
+	return fee
+}
+
+func (cc *tokenChaincode) metadataFee() metadataFee {
+	fee := metadataFee{
+		Fee:   new(big.Int),
+		Floor: new(big.Int),
+		Cap:   new(big.Int),
+	}
+	if cc.feeAddress != nil {
+		fee.Address = encodeAddress(cc.feeAddress)
+	}
+	if cc.fee != nil {
+		fee.Currency = cc.fee.Currency
+		fee.Fee.SetBytes(cc.fee.Fee)
+		fee.Floor.SetBytes(cc.fee.Floor)
+		fee.Cap.SetBytes(cc.fee.Cap)
+	}
+	return fee
+}
+
+func hundredPercent() *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(feeDecimals), nil)
+}
@@ -0,0 +1,69 @@
+package fakeproxy
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	pb "github.com/tickets-dao/integration/proto"
+)
+
+// metadataGroup - group in metadata of industrial token chaincode
+type metadataGroup struct {
+	Name     string    `json:"name"`
+	Amount   *big.Int  `json:"amount"`
+	Maturity time.Time `json:"maturity"`
+	Note     string    `json:"note"`
+}
+
+// redeem - args: group, amount, reference. Owner burns amount of matured group, group before maturity can't be redeemed
+func (s *Server) redeem(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 3
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	if !cc.initialized {
+		return nil, errors.New("token is not initialized")
+	}
+	key, err := cc.ledgerKey(cc.symbol, tx.args[0])
+	if err != nil {
+		return nil, err
+	}
+	group := cc.group(tx.args[0])
+	if maturity := time.Unix(group.Maturity, 0); time.Now().Before(maturity) {
+		return nil, fmt.Errorf("group %s is not matured: maturity %s", group.Id, maturity.UTC().Format(time.RFC3339))
+	}
+	amount, err := parseAmount(tx.args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cc.sub(key, tx.senderAddress(), amount); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// group - group of industrial token by id with or without symbol prefix, nil if it doesn't exist
+func (cc *tokenChaincode) group(id string) *pb.IndustrialGroup {
+	for _, group := range cc.groups {
+		if group.Id == id || cc.symbol+"_"+group.Id == id {
+			return group
+		}
+	}
+	return nil
+}
+
+func (cc *tokenChaincode) metadataGroups() []metadataGroup {
+	groups := make([]metadataGroup, 0, len(cc.groups))
+	for _, group := range cc.groups {
+		groups = append(groups, metadataGroup{
+			Name:     group.Id,
+			Amount:   new(big.Int).SetBytes(group.Emission),
+			Maturity: time.Unix(group.Maturity, 0).UTC(),
+			Note:     group.Note,
+		})
+	}
+	return groups
+}
@@ -0,0 +1,204 @@
+package fakeproxy
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	pb "github.com/tickets-dao/integration/proto"
+)
+
+const (
+	dealBuyToken = "buyToken"
+	dealBuyBack  = "buyBack"
+)
+
+// metadataRate - rate in metadata of token chaincode
+type metadataRate struct {
+	DealType string   `json:"deal_type"`
+	Currency string   `json:"currency"`
+	Rate     *big.Int `json:"rate"`
+	Min      *big.Int `json:"min"`
+	Max      *big.Int `json:"max"`
+}
+
+// setRate - args: deal type, currency, rate. Only issuer can set rate, rate has feeDecimals decimals like fee
+func (s *Server) setRate(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 3
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	if err := s.checkIssuer(tx); err != nil {
+		return nil, err
+	}
+	dealType, currency, err := cc.rateKey(tx.args[0], tx.args[1])
+	if err != nil {
+		return nil, err
+	}
+	rate, err := parseAmount(tx.args[2])
+	if err != nil {
+		return nil, errors.New("trying to set rate = 0")
+	}
+
+	key := dealType + "/" + currency
+	if existing, ok := cc.rates[key]; ok {
+		existing.Rate = rate.Bytes()
+		return nil, nil
+	}
+	cc.rates[key] = &pb.TokenRate{DealType: dealType, Currency: currency, Rate: rate.Bytes()}
+	return nil, nil
+}
+
+// setLimits - args: deal type, currency, min, max. Zero max means no upper limit
+func (s *Server) setLimits(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 4
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	if err := s.checkIssuer(tx); err != nil {
+		return nil, err
+	}
+	dealType, currency, err := cc.rateKey(tx.args[0], tx.args[1])
+	if err != nil {
+		return nil, err
+	}
+	rate, ok := cc.rates[dealType+"/"+currency]
+	if !ok {
+		return nil, fmt.Errorf("rate %s %s not found", dealType, currency)
+	}
+
+	limits := make([]*big.Int, 2)
+	for i, arg := range tx.args[2:] {
+		value, ok := new(big.Int).SetString(arg, 10)
+		if !ok || value.Sign() < 0 {
+			return nil, fmt.Errorf("invalid limit %s", arg)
+		}
+		limits[i] = value
+	}
+	if limits[1].Sign() > 0 && limits[0].Cmp(limits[1]) > 0 {
+		return nil, errors.New("min limit is greater than max limit")
+	}
+	rate.Min, rate.Max = limits[0].Bytes(), limits[1].Bytes()
+	return nil, nil
+}
+
+// deleteRate - args: deal type, currency
+func (s *Server) deleteRate(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 2
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	if err := s.checkIssuer(tx); err != nil {
+		return nil, err
+	}
+	dealType, currency, err := cc.rateKey(tx.args[0], tx.args[1])
+	if err != nil {
+		return nil, err
+	}
+	delete(cc.rates, dealType+"/"+currency)
+	return nil, nil
+}
+
+// buyToken - args: amount, currency. Sender pays price in allowed balance of currency to issuer and receives amount of token
+func (s *Server) buyToken(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	amount, currency, price, err := cc.deal(dealBuyToken, tx.args)
+	if err != nil {
+		return nil, err
+	}
+	if s.issuer == nil {
+		return nil, errors.New("issuer isn't set")
+	}
+	issuer := addressOf(s.issuer)
+
+	if err = cc.move(allowedPrefix+currency, tx.senderAddress(), issuer, price); err != nil {
+		return nil, err
+	}
+	cc.add(cc.symbol, tx.senderAddress(), amount)
+	return nil, nil
+}
+
+// buyBack - args: amount, currency. Sender returns amount of token and receives price in allowed balance of currency from issuer
+func (s *Server) buyBack(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	amount, currency, price, err := cc.deal(dealBuyBack, tx.args)
+	if err != nil {
+		return nil, err
+	}
+	if s.issuer == nil {
+		return nil, errors.New("issuer isn't set")
+	}
+	issuer := addressOf(s.issuer)
+
+	if cc.balance(allowedPrefix+currency, issuer).Cmp(price) < 0 {
+		return nil, fmt.Errorf("insufficient funds of issuer to buy back %s %s", price, currency)
+	}
+	if err = cc.sub(cc.symbol, tx.senderAddress(), amount); err != nil {
+		return nil, err
+	}
+	if err = cc.move(allowedPrefix+currency, issuer, tx.senderAddress(), price); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// deal - amount, currency and price of buyToken or buyBack: amount * rate / 10^feeDecimals,
+// amount must be inside limits of rate
+func (cc *tokenChaincode) deal(dealType string, args []string) (*big.Int, string, *big.Int, error) {
+	const argsLen = 2
+	if len(args) != argsLen {
+		return nil, "", nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(args), argsLen)
+	}
+	amount, err := parseAmount(args[0])
+	if err != nil {
+		return nil, "", nil, err
+	}
+	currency := strings.ToUpper(args[1])
+	rate, ok := cc.rates[dealType+"/"+currency]
+	if !ok {
+		return nil, "", nil, fmt.Errorf("rate %s %s not found", dealType, currency)
+	}
+
+	lower, upper := new(big.Int).SetBytes(rate.Min), new(big.Int).SetBytes(rate.Max)
+	if amount.Cmp(lower) < 0 || (upper.Sign() > 0 && amount.Cmp(upper) > 0) {
+		return nil, "", nil, fmt.Errorf("amount out of limits: %s, min %s, max %s", amount, lower, upper)
+	}
+
+	price := new(big.Int).Mul(amount, new(big.Int).SetBytes(rate.Rate))
+	price.Div(price, hundredPercent())
+	if price.Sign() == 0 {
+		return nil, "", nil, errors.New("price is zero")
+	}
+	return amount, currency, price, nil
+}
+
+func (cc *tokenChaincode) rateKey(dealType, currency string) (string, string, error) {
+	if dealType != dealBuyToken && dealType != dealBuyBack {
+		return "", "", fmt.Errorf("unknown deal type %s", dealType)
+	}
+	currency = strings.ToUpper(currency)
+	if currency == cc.symbol {
+		return "", "", errors.New("currency is equals token: it is impossible")
+	}
+	return dealType, currency, nil
+}
+
+func (cc *tokenChaincode) metadataRates() []metadataRate {
+	rates := make([]metadataRate, 0, len(cc.rates))
+	for _, rate := range cc.rates {
+		rates = append(rates, metadataRate{
+			DealType: rate.DealType,
+			Currency: rate.Currency,
+			Rate:     new(big.Int).SetBytes(rate.Rate),
+			Min:      new(big.Int).SetBytes(rate.Min),
+			Max:      new(big.Int).SetBytes(rate.Max),
+		})
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].DealType != rates[j].DealType {
+			return rates[i].DealType < rates[j].DealType
+		}
+		return rates[i].Currency < rates[j].Currency
+	})
+	return rates
+}
@@ -0,0 +1,156 @@
+package fakeproxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+// changePublicKey - args: address, reason, reason id, new public key in base58, nonce,
+// public keys and signatures of all validators. User keeps address, old public key can't sign anymore
+func (acl *aclChaincode) changePublicKey(args []string) error {
+	const signedLen = 5
+	if err := acl.verifyValidators("changePublicKey", args, signedLen); err != nil {
+		return err
+	}
+
+	user, err := acl.userByAddress(args[0])
+	if err != nil {
+		return err
+	}
+	if user.policy != nil {
+		return fmt.Errorf("address %s is multisig, use changeMultisigPublicKey", args[0])
+	}
+	reasonID, err := parseReasonID(args[2])
+	if err != nil {
+		return err
+	}
+	newKey, err := parsePublicKey(args[3])
+	if err != nil {
+		return err
+	}
+	if _, ok := acl.users[newKey]; ok {
+		return fmt.Errorf("user with public key %s already exists", newKey)
+	}
+
+	delete(acl.users, user.publicKey)
+	user.publicKey = newKey
+	user.signedTx = append([]string{"changePublicKey"}, args...)
+	user.reason, user.reasonID = args[1], reasonID
+	acl.users[newKey] = user
+	return nil
+}
+
+// changeMultisigPublicKey - args: multisig address, old public key and new public key of member in base58,
+// reason, reason id, nonce, public keys and signatures of all validators. Multisig keeps address and N
+func (acl *aclChaincode) changeMultisigPublicKey(args []string) error {
+	const signedLen = 6
+	if err := acl.verifyValidators("changeMultisigPublicKey", args, signedLen); err != nil {
+		return err
+	}
+
+	user, err := acl.userByAddress(args[0])
+	if err != nil {
+		return err
+	}
+	if user.policy == nil {
+		return fmt.Errorf("address %s isn't multisig", args[0])
+	}
+	oldKey, err := parsePublicKey(args[1])
+	if err != nil {
+		return err
+	}
+	newKey, err := parsePublicKey(args[2])
+	if err != nil {
+		return err
+	}
+	if _, err = parseReasonID(args[4]); err != nil {
+		return err
+	}
+
+	keys := user.policy.PubKeys
+	i := -1
+	for j, key := range keys {
+		if bytes.Equal(key, base58.Decode(oldKey)) {
+			i = j
+		}
+		if bytes.Equal(key, base58.Decode(newKey)) {
+			return fmt.Errorf("public key %s is already member of multisig", newKey)
+		}
+	}
+	if i < 0 {
+		return fmt.Errorf("public key %s isn't member of multisig", oldKey)
+	}
+	keys[i] = base58.Decode(newKey)
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	encoded := make([]string, len(keys))
+	for j, key := range keys {
+		encoded[j] = base58.Encode(key)
+	}
+	delete(acl.users, user.publicKey)
+	user.publicKey = strings.Join(encoded, "/")
+	user.policy.ReplaceKeysSignedTx = append([]string{"changeMultisigPublicKey"}, args...)
+	acl.users[user.publicKey] = user
+	return nil
+}
+
+// verifyValidators - first signedLen args are followed by public keys and signatures of validators,
+// message is sha3 digest of method and signed args, every validator must sign
+func (acl *aclChaincode) verifyValidators(method string, args []string, signedLen int) error {
+	if len(acl.validators) == 0 {
+		return errors.New("validators aren't set")
+	}
+	if len(args) < signedLen+2 || (len(args)-signedLen)%2 != 0 {
+		return fmt.Errorf("incorrect number of arguments: %d", len(args))
+	}
+
+	k := (len(args) - signedLen) / 2
+	publicKeys, sigs := args[signedLen:signedLen+k], args[signedLen+k:]
+	message := sha3.Sum256([]byte(method + strings.Join(args[:signedLen], "")))
+	signed := make(map[string]bool, k)
+	for i, publicKey := range publicKeys {
+		if !acl.isValidator(publicKey) {
+			return fmt.Errorf("public key %s isn't validator", publicKey)
+		}
+		if !ed25519.Verify(base58.Decode(publicKey), message[:], base58.Decode(sigs[i])) {
+			return fmt.Errorf("incorrect signature of validator %s", publicKey)
+		}
+		signed[publicKey] = true
+	}
+	if len(signed) < len(acl.validators) {
+		return fmt.Errorf("insufficient number of signatures of validators %d, expected %d", len(signed), len(acl.validators))
+	}
+	return nil
+}
+
+func (acl *aclChaincode) isValidator(publicKey string) bool {
+	for _, validator := range acl.validators {
+		if base58.Encode(validator) == publicKey {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePublicKey(publicKey string) (string, error) {
+	if len(base58.Decode(publicKey)) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("incorrect public key %s", publicKey)
+	}
+	return publicKey, nil
+}
+
+func parseReasonID(reasonID string) (int32, error) {
+	id, err := strconv.ParseInt(reasonID, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("incorrect reason id %s: %w", reasonID, err)
+	}
+	return int32(id), nil
+}
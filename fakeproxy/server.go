@@ -0,0 +1,245 @@
+// Package fakeproxy - in-memory stand-in of hlf proxy service with acl, fiat, cc and industrial chaincodes.
+// It serves the same '/invoke' and '/query' contract as real hlf proxy service, so integration tests
+// can be executed without fabric network
+package fakeproxy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tickets-dao/integration/utils"
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// FiatNonceTTL - nonce ttl of fiat chaincode
+	FiatNonceTTL = 10 * time.Second
+	// CCNonceTTL - nonce ttl of cc chaincode
+	CCNonceTTL = 10 * time.Second
+	// IndustrialNonceTTL - nonce ttl of industrial chaincode, every nonce must be greater than previous
+	IndustrialNonceTTL = 0
+
+	txIDLength = 32
+)
+
+// Server - fake hlf proxy service
+type Server struct {
+	server    *httptest.Server
+	authToken string
+	issuer    ed25519.PublicKey
+	// manualBatch - transactions wait for batchExecute of robot instead of immediate execution
+	manualBatch bool
+
+	mu         sync.Mutex
+	acl        *aclChaincode
+	chaincodes map[string]*tokenChaincode
+}
+
+// Option - configures Server
+type Option func(s *Server)
+
+// WithAuthToken - token expected in Basic Auth header, any token is accepted if it is empty
+func WithAuthToken(token string) Option {
+	return func(s *Server) {
+		s.authToken = token
+	}
+}
+
+// WithIssuer - public key allowed to emit tokens and initialize industrial chaincode
+func WithIssuer(publicKey ed25519.PublicKey) Option {
+	return func(s *Server) {
+		s.issuer = publicKey
+	}
+}
+
+// WithValidators - public keys of acl validators, all of them must sign changePublicKey and changeMultisigPublicKey
+func WithValidators(publicKeys ...ed25519.PublicKey) Option {
+	return func(s *Server) {
+		s.acl.validators = publicKeys
+	}
+}
+
+// WithNonceTTL - override nonce ttl of chaincode
+func WithNonceTTL(chaincode string, ttl time.Duration) Option {
+	return func(s *Server) {
+		if cc, ok := s.chaincodes[chaincode]; ok {
+			cc.nonceTTL = ttl
+		}
+	}
+}
+
+// WithManualBatch - keep transactions pending until batchExecute is invoked like robot does,
+// swaps and swap keys are delivered between channels only by batches
+func WithManualBatch() Option {
+	return func(s *Server) {
+		s.manualBatch = true
+	}
+}
+
+// New - start fake hlf proxy service, Close must be called after use
+func New(opts ...Option) *Server {
+	s := &Server{
+		acl: newACLChaincode(),
+		chaincodes: map[string]*tokenChaincode{
+			"fiat":       newTokenChaincode("fiat", "FIAT", FiatNonceTTL),
+			"cc":         newTokenChaincode("cc", "CC", CCNonceTTL),
+			"industrial": newIndustrialChaincode("industrial", "INDUSTRIAL", IndustrialNonceTTL, defaultGroups()),
+		},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/invoke", s.handle(s.invoke))
+	mux.HandleFunc("/query", s.handle(s.query))
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// URL - base url of fake hlf proxy service without '/' on the end the string
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close - stop fake hlf proxy service
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+type requestHandler func(req *utils.Request) (*utils.Response, error)
+
+func (s *Server) handle(h requestHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		if s.authToken != "" && r.Header.Get("authorization") != "Basic "+s.authToken {
+			writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("read body: %w", err))
+			return
+		}
+
+		req := &utils.Request{}
+		if err = json.Unmarshal(body, req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("json unmarshal: %w", err))
+			return
+		}
+
+		s.mu.Lock()
+		resp, err := h(req)
+		s.mu.Unlock()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *Server) invoke(req *utils.Request) (*utils.Response, error) {
+	args := asStrings(req.Args)
+	txID, err := newTxID()
+	if err != nil {
+		return nil, err
+	}
+
+	var payload []byte
+	if req.ChaincodeID == aclName {
+		payload, err = s.acl.invoke(req.Fcn, args)
+	} else {
+		cc, ok := s.chaincodes[req.ChaincodeID]
+		if !ok {
+			return nil, fmt.Errorf("chaincode %s not found", req.ChaincodeID)
+		}
+		if req.Fcn == batchExecuteFn {
+			payload, err = s.batchExecute(cc, args)
+		} else {
+			payload, err = s.invokeToken(cc, txID, req.Fcn, args)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.Response{
+		Payload:       payload,
+		TransactionID: txID,
+	}, nil
+}
+
+func (s *Server) query(req *utils.Request) (*utils.Response, error) {
+	args := asStrings(req.Args)
+
+	var (
+		payload []byte
+		err     error
+	)
+	if req.ChaincodeID == aclName {
+		payload, err = s.acl.query(req.Fcn, args)
+	} else {
+		cc, ok := s.chaincodes[req.ChaincodeID]
+		if !ok {
+			return nil, fmt.Errorf("chaincode %s not found", req.ChaincodeID)
+		}
+		payload, err = cc.query(req.Fcn, args)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.Response{Payload: payload}, nil
+}
+
+// chaincodeBySymbol - find chaincode by channel name used in swap arguments, example 'CC' or 'FIAT'
+func (s *Server) chaincodeBySymbol(channel string) (*tokenChaincode, bool) {
+	for _, cc := range s.chaincodes {
+		if strings.EqualFold(cc.name, channel) || strings.EqualFold(cc.symbol, channel) {
+			return cc, true
+		}
+	}
+	return nil, false
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(utils.ResponseError{
+		Code:    int64(status),
+		Message: err.Error(),
+	})
+}
+
+func asStrings(args [][]byte) []string {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		result[i] = string(arg)
+	}
+	return result
+}
+
+func newTxID() (string, error) {
+	id := make([]byte, txIDLength)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("generate tx id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
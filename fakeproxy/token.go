@@ -0,0 +1,719 @@
+package fakeproxy
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang/protobuf/proto"
+	pb "github.com/tickets-dao/integration/proto"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	swapTimeout = 3 * time.Hour
+
+	allowedPrefix = "allowed/"
+)
+
+// tokenChaincode - state of token chaincode built on foundation library
+type tokenChaincode struct {
+	name     string
+	symbol   string
+	nonceTTL time.Duration
+	methods  map[string]txMethod
+
+	// groups - groups of industrial token, empty for plain token
+	groups      []*pb.IndustrialGroup
+	initialized bool
+
+	// fee - fee of transfer set by setFee, nil if fee isn't set
+	fee        *pb.TokenFee
+	feeAddress []byte
+	// rates - rates of buyToken and buyBack by deal type and currency
+	rates map[string]*pb.TokenRate
+
+	// ledger - balances by ledger key and address, see ledgerKey
+	ledger     map[string]map[string]*big.Int
+	swaps      map[string]*pb.Swap
+	multiSwaps map[string]*pb.MultiSwap
+	// nonces - sorted nonces of address inside nonce ttl window
+	nonces map[string][]int64
+	events map[string]*pb.BatchTxEvent
+
+	// pending - transactions waiting for batchExecute in manual batch mode
+	pending map[string]*transaction
+	// createdSwaps - swaps started since last batchExecute in manual batch mode, returned to robot in batch response
+	createdSwaps      []*pb.Swap
+	createdMultiSwaps []*pb.MultiSwap
+	// accounting - balance changes of transaction being executed, saved into batch event of transaction
+	accounting []*pb.AccountingRecord
+}
+
+func newTokenChaincode(name, symbol string, nonceTTL time.Duration) *tokenChaincode {
+	cc := &tokenChaincode{
+		name:       name,
+		symbol:     symbol,
+		nonceTTL:   nonceTTL,
+		ledger:     make(map[string]map[string]*big.Int),
+		swaps:      make(map[string]*pb.Swap),
+		multiSwaps: make(map[string]*pb.MultiSwap),
+		nonces:     make(map[string][]int64),
+		events:     make(map[string]*pb.BatchTxEvent),
+		pending:    make(map[string]*transaction),
+		rates:      make(map[string]*pb.TokenRate),
+	}
+	cc.methods = map[string]txMethod{
+		"emit":           {signed: true, exec: (*Server).emit},
+		"transfer":       {signed: true, exec: (*Server).transfer},
+		"swapBegin":      {signed: true, exec: (*Server).swapBegin},
+		"swapDone":       {direct: true, exec: (*Server).swapDone},
+		"swapCancel":     {signed: true, exec: (*Server).swapCancel},
+		"multiSwapBegin": {signed: true, exec: (*Server).multiSwapBegin},
+		"multiSwapDone":  {direct: true, exec: (*Server).multiSwapDone},
+		"setFee":         {signed: true, exec: (*Server).setFee},
+		"setFeeAddress":  {signed: true, exec: (*Server).setFeeAddress},
+		"setRate":        {signed: true, exec: (*Server).setRate},
+		"setLimits":      {signed: true, exec: (*Server).setLimits},
+		"deleteRate":     {signed: true, exec: (*Server).deleteRate},
+		"buyToken":       {signed: true, exec: (*Server).buyToken},
+		"buyBack":        {signed: true, exec: (*Server).buyBack},
+	}
+	return cc
+}
+
+func newIndustrialChaincode(name, symbol string, nonceTTL time.Duration, groups []*pb.IndustrialGroup) *tokenChaincode {
+	cc := newTokenChaincode(name, symbol, nonceTTL)
+	cc.groups = groups
+	delete(cc.methods, "emit")
+	delete(cc.methods, "transfer")
+	cc.methods["initialize"] = txMethod{signed: true, exec: (*Server).initialize}
+	cc.methods["transferIndustrial"] = txMethod{signed: true, exec: (*Server).transferIndustrial}
+	cc.methods["redeem"] = txMethod{signed: true, exec: (*Server).redeem}
+	return cc
+}
+
+func defaultGroups() []*pb.IndustrialGroup {
+	const emission = 100000
+	return []*pb.IndustrialGroup{
+		{Id: "202010", Emission: big.NewInt(emission).Bytes(), Maturity: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC).Unix()},
+		{Id: "202101", Emission: big.NewInt(emission).Bytes(), Maturity: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Unix()},
+		{Id: "210001", Emission: big.NewInt(emission).Bytes(), Maturity: time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC).Unix(), Note: "long term group"},
+	}
+}
+
+func (cc *tokenChaincode) isIndustrial() bool {
+	return len(cc.groups) != 0
+}
+
+func (cc *tokenChaincode) query(fcn string, args []string) ([]byte, error) {
+	switch fcn {
+	case "metadata":
+		return cc.metadata()
+	case "balanceOf":
+		return cc.balanceOf(args)
+	case "allowedBalanceOf":
+		return cc.allowedBalanceOf(args)
+	case "industrialBalanceOf":
+		return cc.industrialBalanceOf(args)
+	case "swapGet":
+		return cc.swapGet(args)
+	case "multiSwapGet":
+		return cc.multiSwapGet(args)
+	case "getBatchTxEvent":
+		return cc.getBatchTxEvent(args)
+	default:
+		return nil, fmt.Errorf("query method %s not found in chaincode %s", fcn, cc.name)
+	}
+}
+
+func (cc *tokenChaincode) metadata() ([]byte, error) {
+	methods := []string{"metadata", "balanceOf", "allowedBalanceOf", "swapGet", "multiSwapGet", "getBatchTxEvent"}
+	if cc.isIndustrial() {
+		methods = append(methods, "industrialBalanceOf")
+	}
+	for method := range cc.methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	md := struct {
+		Name        string          `json:"name"`
+		Symbol      string          `json:"symbol"`
+		Methods     []string        `json:"methods"`
+		Fee         metadataFee     `json:"fee"`
+		Rates       []metadataRate  `json:"rates"`
+		Groups      []metadataGroup `json:"groups,omitempty"`
+		Initialized *bool           `json:"initialized,omitempty"`
+	}{
+		Name:    cc.name,
+		Symbol:  cc.symbol,
+		Methods: methods,
+		Fee:     cc.metadataFee(),
+		Rates:   cc.metadataRates(),
+	}
+	if cc.isIndustrial() {
+		md.Groups = cc.metadataGroups()
+		md.Initialized = &cc.initialized
+	}
+	return json.Marshal(md)
+}
+
+// balanceOf - args: address
+func (cc *tokenChaincode) balanceOf(args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(args))
+	}
+	if _, err := decodeAddress(args[0]); err != nil {
+		return nil, err
+	}
+	return json.Marshal(cc.balance(cc.symbol, args[0]).String())
+}
+
+// allowedBalanceOf - args: address, token
+func (cc *tokenChaincode) allowedBalanceOf(args []string) ([]byte, error) {
+	const argsLen = 2
+	if len(args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(args), argsLen)
+	}
+	if _, err := decodeAddress(args[0]); err != nil {
+		return nil, err
+	}
+	return json.Marshal(cc.balance(allowedPrefix+strings.ToUpper(args[1]), args[0]).String())
+}
+
+// industrialBalanceOf - args: address
+func (cc *tokenChaincode) industrialBalanceOf(args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(args))
+	}
+	if _, err := decodeAddress(args[0]); err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]string)
+	for _, group := range cc.groups {
+		balance := cc.balance(cc.symbol+"_"+group.Id, args[0])
+		if balance.Sign() != 0 {
+			balances[group.Id] = balance.String()
+		}
+	}
+	return json.Marshal(balances)
+}
+
+// swapGet - args: swap id
+func (cc *tokenChaincode) swapGet(args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(args))
+	}
+	swap, ok := cc.swaps[args[0]]
+	if !ok {
+		return nil, fmt.Errorf("swap doesn't exist by key %s", args[0])
+	}
+	return json.Marshal(swap)
+}
+
+// multiSwapGet - args: multi swap id
+func (cc *tokenChaincode) multiSwapGet(args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(args))
+	}
+	swap, ok := cc.multiSwaps[args[0]]
+	if !ok {
+		return nil, fmt.Errorf("multiswap doesn't exist by key %s", args[0])
+	}
+	return json.Marshal(swap)
+}
+
+// emit - args: address, amount. Only issuer can emit token
+func (s *Server) emit(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 2
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	if err := s.checkIssuer(tx); err != nil {
+		return nil, err
+	}
+	if _, err := decodeAddress(tx.args[0]); err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(tx.args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	cc.add(cc.symbol, tx.args[0], amount)
+	return nil, nil
+}
+
+// transfer - args: address, amount, reference. Sender pays fee to fee address in addition to amount
+func (s *Server) transfer(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 3
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	to := tx.args[0]
+	if _, err := decodeAddress(to); err != nil {
+		return nil, err
+	}
+	if to == tx.senderAddress() {
+		return nil, errors.New("sender and recipient are same users")
+	}
+	if err := s.acl.checkBlackList(tx.senderAddress(), to); err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(tx.args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	fee := cc.transferFee(amount)
+	if fee.Sign() > 0 {
+		if cc.feeAddress == nil {
+			return nil, errors.New("fee address isn't set")
+		}
+		total := new(big.Int).Add(amount, fee)
+		if balance := cc.balance(cc.symbol, tx.senderAddress()); balance.Cmp(total) < 0 {
+			return nil, fmt.Errorf("insufficient funds to process: balance %s, amount %s, fee %s", balance, amount, fee)
+		}
+	}
+
+	if err = cc.move(cc.symbol, tx.senderAddress(), to, amount); err != nil {
+		return nil, err
+	}
+	if fee.Sign() > 0 {
+		if err = cc.move(cc.symbol, tx.senderAddress(), encodeAddress(cc.feeAddress), fee); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// swapBegin - args: token, channel to, amount, hash of swap key in hex
+func (s *Server) swapBegin(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 4
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	if err := s.acl.checkBlackList(tx.senderAddress()); err != nil {
+		return nil, err
+	}
+	token := strings.ToUpper(tx.args[0])
+	to, err := s.swapDestination(cc, tx.args[1])
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(tx.args[2])
+	if err != nil {
+		return nil, err
+	}
+	hash, err := parseHash(tx.args[3])
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := cc.ledgerKey(token, "")
+	if err != nil {
+		return nil, err
+	}
+	if err = cc.sub(key, tx.senderAddress(), amount); err != nil {
+		return nil, err
+	}
+
+	id, _ := hex.DecodeString(tx.id)
+	swap := &pb.Swap{
+		Id:      id,
+		Creator: tx.sender.address,
+		Owner:   tx.sender.address,
+		Token:   token,
+		Amount:  amount.Bytes(),
+		From:    cc.symbol,
+		To:      to.symbol,
+		Hash:    hash,
+		Timeout: time.Now().Add(swapTimeout).Unix(),
+	}
+	cc.swaps[tx.id] = swap
+	if s.manualBatch {
+		cc.createdSwaps = append(cc.createdSwaps, proto.Clone(swap).(*pb.Swap))
+	} else {
+		to.swaps[tx.id] = proto.Clone(swap).(*pb.Swap)
+	}
+
+	return nil, nil
+}
+
+// swapDone - args: swap id, swap key. Must be called in channel swap is directed to.
+// In manual batch mode swap is removed from source channel when robot delivers swap key
+func (s *Server) swapDone(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 2
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	id, swapKey := tx.args[0], tx.args[1]
+	swap, ok := cc.swaps[id]
+	if !ok || swap.To != cc.symbol {
+		return nil, fmt.Errorf("swap doesn't exist by key %s", id)
+	}
+	if err := checkSwapKey(swap.Hash, swapKey); err != nil {
+		return nil, err
+	}
+
+	key, err := cc.ledgerKey(swap.Token, "")
+	if err != nil {
+		return nil, err
+	}
+	cc.add(key, encodeAddress(swap.Owner), new(big.Int).SetBytes(swap.Amount))
+
+	delete(cc.swaps, id)
+	if from, ok := s.chaincodeBySymbol(swap.From); ok && !s.manualBatch {
+		delete(from.swaps, id)
+	}
+	return nil, nil
+}
+
+// swapCancel - args: swap id. Must be called by owner in channel swap is started from, amount is returned to owner
+func (s *Server) swapCancel(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	if len(tx.args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(tx.args))
+	}
+	id := tx.args[0]
+	swap, ok := cc.swaps[id]
+	if !ok || swap.From != cc.symbol {
+		return nil, fmt.Errorf("swap doesn't exist by key %s", id)
+	}
+	if !bytes.Equal(swap.Owner, tx.sender.address) {
+		return nil, errors.New("unauthorized, only owner can cancel swap")
+	}
+
+	key, err := cc.ledgerKey(swap.Token, "")
+	if err != nil {
+		return nil, err
+	}
+	cc.add(key, encodeAddress(swap.Owner), new(big.Int).SetBytes(swap.Amount))
+
+	delete(cc.swaps, id)
+	if to, ok := s.chaincodeBySymbol(swap.To); ok {
+		delete(to.swaps, id)
+	}
+	return nil, nil
+}
+
+// multiSwapAssets - multi swap assets argument in format {"Assets":[{"group":"FIAT","amount":"1"}]}
+type multiSwapAssets struct {
+	Assets []struct {
+		Group  string `json:"group"`
+		Amount string `json:"amount"`
+	} `json:"Assets"`
+}
+
+// multiSwapBegin - args: token, assets, channel to, hash of swap key in hex
+func (s *Server) multiSwapBegin(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 4
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	if err := s.acl.checkBlackList(tx.senderAddress()); err != nil {
+		return nil, err
+	}
+	token := strings.ToUpper(tx.args[0])
+	var assets multiSwapAssets
+	if err := json.Unmarshal([]byte(tx.args[1]), &assets); err != nil {
+		return nil, fmt.Errorf("invalid assets: %w", err)
+	}
+	if len(assets.Assets) == 0 {
+		return nil, errors.New("assets can't be empty")
+	}
+	to, err := s.swapDestination(cc, tx.args[2])
+	if err != nil {
+		return nil, err
+	}
+	hash, err := parseHash(tx.args[3])
+	if err != nil {
+		return nil, err
+	}
+
+	sender := tx.senderAddress()
+	keys := make([]string, len(assets.Assets))
+	amounts := make([]*big.Int, len(assets.Assets))
+	pbAssets := make([]*pb.Asset, len(assets.Assets))
+	for i, asset := range assets.Assets {
+		if keys[i], err = cc.ledgerKey(token, asset.Group); err != nil {
+			return nil, err
+		}
+		if amounts[i], err = parseAmount(asset.Amount); err != nil {
+			return nil, err
+		}
+		if cc.balance(keys[i], sender).Cmp(amounts[i]) < 0 {
+			return nil, fmt.Errorf("insufficient funds to process multiswap of group %s", asset.Group)
+		}
+		pbAssets[i] = &pb.Asset{Group: asset.Group, Amount: amounts[i].Bytes()}
+	}
+	for i := range keys {
+		if err = cc.sub(keys[i], sender, amounts[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	id, _ := hex.DecodeString(tx.id)
+	swap := &pb.MultiSwap{
+		Id:      id,
+		Creator: tx.sender.address,
+		Owner:   tx.sender.address,
+		Token:   token,
+		From:    cc.symbol,
+		To:      to.symbol,
+		Hash:    hash,
+		Timeout: time.Now().Add(swapTimeout).Unix(),
+		Assets:  pbAssets,
+	}
+	cc.multiSwaps[tx.id] = swap
+	if s.manualBatch {
+		cc.createdMultiSwaps = append(cc.createdMultiSwaps, proto.Clone(swap).(*pb.MultiSwap))
+	} else {
+		to.multiSwaps[tx.id] = proto.Clone(swap).(*pb.MultiSwap)
+	}
+
+	return nil, nil
+}
+
+// multiSwapDone - args: multi swap id, swap key. Must be called in channel multi swap is directed to.
+// In manual batch mode multi swap is removed from source channel when robot delivers swap key
+func (s *Server) multiSwapDone(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 2
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	id, swapKey := tx.args[0], tx.args[1]
+	swap, ok := cc.multiSwaps[id]
+	if !ok || swap.To != cc.symbol {
+		return nil, fmt.Errorf("multiswap doesn't exist by key %s", id)
+	}
+	if err := checkSwapKey(swap.Hash, swapKey); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(swap.Assets))
+	for i, asset := range swap.Assets {
+		var err error
+		if keys[i], err = cc.ledgerKey(swap.Token, asset.Group); err != nil {
+			return nil, err
+		}
+	}
+	owner := encodeAddress(swap.Owner)
+	for i, asset := range swap.Assets {
+		cc.add(keys[i], owner, new(big.Int).SetBytes(asset.Amount))
+	}
+
+	delete(cc.multiSwaps, id)
+	if from, ok := s.chaincodeBySymbol(swap.From); ok && !s.manualBatch {
+		delete(from.multiSwaps, id)
+	}
+	return nil, nil
+}
+
+// initialize - no args. Only issuer can initialize industrial token and receives emission of every group
+func (s *Server) initialize(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	if err := s.checkIssuer(tx); err != nil {
+		return nil, err
+	}
+	if cc.initialized {
+		return nil, nil
+	}
+
+	for _, group := range cc.groups {
+		cc.add(cc.symbol+"_"+group.Id, tx.senderAddress(), new(big.Int).SetBytes(group.Emission))
+	}
+	cc.initialized = true
+	return nil, nil
+}
+
+// transferIndustrial - args: address, group, amount, reference
+func (s *Server) transferIndustrial(cc *tokenChaincode, tx *transaction) ([]byte, error) {
+	const argsLen = 4
+	if len(tx.args) != argsLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected %d", len(tx.args), argsLen)
+	}
+	if !cc.initialized {
+		return nil, errors.New("token is not initialized")
+	}
+	to := tx.args[0]
+	if _, err := decodeAddress(to); err != nil {
+		return nil, err
+	}
+	if to == tx.senderAddress() {
+		return nil, errors.New("sender and recipient are same users")
+	}
+	key, err := cc.ledgerKey(cc.symbol, tx.args[1])
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(tx.args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cc.move(key, tx.senderAddress(), to, amount); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *Server) checkIssuer(tx *transaction) error {
+	if s.issuer != nil && tx.sender.publicKey != base58.Encode(s.issuer) {
+		return errors.New("unauthorized")
+	}
+	return nil
+}
+
+func (s *Server) swapDestination(cc *tokenChaincode, channel string) (*tokenChaincode, error) {
+	to, ok := s.chaincodeBySymbol(channel)
+	if !ok {
+		return nil, fmt.Errorf("channel %s not found", channel)
+	}
+	if to == cc {
+		return nil, fmt.Errorf("swap to the same channel %s", channel)
+	}
+	return to, nil
+}
+
+// ledgerKey - key of balances in ledger.
+// Own token is stored by symbol and by symbol with group for industrial token,
+// token of other channels is stored as allowed balance
+func (cc *tokenChaincode) ledgerKey(token, group string) (string, error) {
+	token = strings.ToUpper(token)
+	group = strings.TrimPrefix(group, token+"_")
+	if group == token {
+		group = ""
+	}
+
+	if token != cc.symbol {
+		if group == "" {
+			return allowedPrefix + token, nil
+		}
+		return allowedPrefix + token + "_" + group, nil
+	}
+
+	if group == "" {
+		if cc.isIndustrial() {
+			return "", fmt.Errorf("group of token %s is required", token)
+		}
+		return cc.symbol, nil
+	}
+	for _, g := range cc.groups {
+		if g.Id == group {
+			return cc.symbol + "_" + group, nil
+		}
+	}
+	return "", fmt.Errorf("group %s of token %s not found", group, token)
+}
+
+func (cc *tokenChaincode) balance(key, address string) *big.Int {
+	if balance, ok := cc.ledger[key][address]; ok {
+		return new(big.Int).Set(balance)
+	}
+	return new(big.Int)
+}
+
+func (cc *tokenChaincode) add(key, address string, amount *big.Int) {
+	cc.credit(key, address, amount)
+	cc.record(key, "", address, amount)
+}
+
+func (cc *tokenChaincode) sub(key, address string, amount *big.Int) error {
+	if err := cc.debit(key, address, amount); err != nil {
+		return err
+	}
+	cc.record(key, address, "", amount)
+	return nil
+}
+
+// move - transfer amount between addresses with single accounting record
+func (cc *tokenChaincode) move(key, from, to string, amount *big.Int) error {
+	if err := cc.debit(key, from, amount); err != nil {
+		return err
+	}
+	cc.credit(key, to, amount)
+	cc.record(key, from, to, amount)
+	return nil
+}
+
+func (cc *tokenChaincode) credit(key, address string, amount *big.Int) {
+	if cc.ledger[key] == nil {
+		cc.ledger[key] = make(map[string]*big.Int)
+	}
+	cc.ledger[key][address] = new(big.Int).Add(cc.balance(key, address), amount)
+}
+
+func (cc *tokenChaincode) debit(key, address string, amount *big.Int) error {
+	balance := cc.balance(key, address)
+	if balance.Cmp(amount) < 0 {
+		return fmt.Errorf("insufficient funds to process: balance %s, amount %s", balance, amount)
+	}
+	cc.ledger[key][address] = balance.Sub(balance, amount)
+	return nil
+}
+
+// record - accounting record of balance change, token is ledger key without allowed prefix.
+// Empty sender is emission and empty recipient is withdrawal of amount from channel
+func (cc *tokenChaincode) record(key, sender, recipient string, amount *big.Int) {
+	record := &pb.AccountingRecord{
+		Token:  strings.TrimPrefix(key, allowedPrefix),
+		Amount: amount.Bytes(),
+	}
+	if sender != "" {
+		record.Sender, _ = decodeAddress(sender)
+	}
+	if recipient != "" {
+		record.Recipient, _ = decodeAddress(recipient)
+	}
+	cc.accounting = append(cc.accounting, record)
+}
+
+func parseAmount(amount string) (*big.Int, error) {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok || value.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount %s", amount)
+	}
+	return value, nil
+}
+
+func parseHash(hash string) ([]byte, error) {
+	decoded, err := hex.DecodeString(hash)
+	if err != nil || len(decoded) != sha3.New256().Size() {
+		return nil, fmt.Errorf("invalid hash %s", hash)
+	}
+	return decoded, nil
+}
+
+func checkSwapKey(hash []byte, key string) error {
+	sum := sha3.Sum256([]byte(key))
+	if !bytes.Equal(hash, sum[:]) {
+		return errors.New("incorrect swap key")
+	}
+	return nil
+}
+
+func decodeAddress(address string) ([]byte, error) {
+	decoded, ver, err := base58.CheckDecode(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	return append([]byte{ver}, decoded...), nil
+}
+
+// addressOf - address of public key in base58 check
+func addressOf(publicKey []byte) string {
+	hash := sha3.Sum256(publicKey)
+	return encodeAddress(hash[:])
+}
+
+func encodeAddress(address []byte) string {
+	return base58.CheckEncode(address[1:], address[0])
+}
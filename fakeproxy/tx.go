@@ -0,0 +1,253 @@
+package fakeproxy
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang/protobuf/proto"
+	pb "github.com/tickets-dao/integration/proto"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+// signedArgsServiceLen - number of service arguments added by utils.Sign:
+// empty slot, chaincode, channel, nonce, public key and signature. Multisig adds public key and signature of every member
+const signedArgsServiceLen = 6
+
+// transaction - invoke of chaincode method executed in batch
+type transaction struct {
+	id     string
+	method string
+	// args - arguments of method without service arguments added by utils.Sign
+	args []string
+	// sender - signer of transaction, nil if method isn't signed
+	sender *aclUser
+	nonce  int64
+}
+
+// senderAddress - sender address in base58 check
+func (tx *transaction) senderAddress() string {
+	return encodeAddress(tx.sender.address)
+}
+
+type txMethod struct {
+	signed bool
+	// direct - method is executed without batch in manual batch mode, like swapDone of foundation
+	direct bool
+	exec   func(s *Server, cc *tokenChaincode, tx *transaction) ([]byte, error)
+}
+
+// invokeToken - validate transaction like foundation does before batch and execute it in batch immediately.
+// In manual batch mode transaction waits for batchExecute
+func (s *Server) invokeToken(cc *tokenChaincode, txID, fcn string, args []string) ([]byte, error) {
+	method, ok := cc.methods[fcn]
+	if !ok {
+		return nil, fmt.Errorf("invoke method %s not found in chaincode %s", fcn, cc.name)
+	}
+
+	tx := &transaction{
+		id:     txID,
+		method: fcn,
+		args:   args,
+	}
+	if method.signed {
+		var err error
+		if tx, err = s.parseSignedTx(cc, txID, fcn, args); err != nil {
+			return nil, err
+		}
+		if cc.nonceTTL == 0 {
+			if err = cc.checkNonce(tx.senderAddress(), tx.nonce); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if s.manualBatch {
+		if method.direct {
+			defer func() { cc.accounting = nil }()
+			return method.exec(s, cc, tx)
+		}
+		cc.pending[txID] = tx
+		return nil, nil
+	}
+
+	s.executeBatchTx(cc, tx, method)
+	return nil, nil
+}
+
+// executeBatchTx - execute transaction like robot does and save batch event of transaction
+func (s *Server) executeBatchTx(cc *tokenChaincode, tx *transaction, method txMethod) *pb.BatchTxEvent {
+	id, _ := hex.DecodeString(tx.id)
+	event := &pb.BatchTxEvent{
+		Id:     id,
+		Method: tx.method,
+	}
+
+	var err error
+	if method.signed && cc.nonceTTL != 0 {
+		err = cc.checkNonce(tx.senderAddress(), tx.nonce)
+	}
+	if err == nil {
+		event.Result, err = method.exec(s, cc, tx)
+	}
+	if err != nil {
+		event.Error = &pb.ResponseError{
+			Code:  http.StatusInternalServerError,
+			Error: err.Error(),
+		}
+	} else {
+		for _, record := range cc.accounting {
+			record.Reason = tx.method
+		}
+		event.Accounting = cc.accounting
+	}
+	cc.accounting = nil
+
+	cc.events[tx.id] = event
+	return event
+}
+
+// parseSignedTx - check arguments produced by utils.SignedTx or utils.MultisigTx: chaincode, channel,
+// signatures and sender in acl
+func (s *Server) parseSignedTx(cc *tokenChaincode, txID, fcn string, args []string) (*transaction, error) {
+	if len(args) < signedArgsServiceLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected at least %d", len(args), signedArgsServiceLen)
+	}
+
+	n := len(args)
+	if args[1] != cc.name || args[2] != cc.name {
+		return nil, fmt.Errorf("incorrect chaincode %s or channel %s, expected %s", args[1], args[2], cc.name)
+	}
+
+	k := signersCount(args)
+	if k == 0 {
+		return nil, errors.New("incorrect public keys of signers")
+	}
+	nonceIndex := n - 2*k - 1
+
+	nonce, err := strconv.ParseInt(args[nonceIndex], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect nonce: %w", err)
+	}
+
+	publicKeys, sigs := args[n-2*k:n-k], args[n-k:]
+	sender, err := s.acl.user(strings.Join(publicKeys, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if sender.grayListed {
+		return nil, fmt.Errorf("address %s is graylisted", encodeAddress(sender.address))
+	}
+
+	message := sha3.Sum256([]byte(fcn + strings.Join(args[:n-k], "")))
+	if err = verifySignatures(sender, publicKeys, sigs, message[:]); err != nil {
+		return nil, err
+	}
+
+	return &transaction{
+		id:     txID,
+		method: fcn,
+		args:   args[3:nonceIndex],
+		sender: sender,
+		nonce:  nonce,
+	}, nil
+}
+
+// signersCount - number of public keys in the end of signed arguments followed by the same number of signatures,
+// 0 if arguments have no public keys
+func signersCount(args []string) int {
+	// nonce follows empty slot, chaincode, channel and method args
+	const nonceMinIndex = 3
+
+	n := len(args)
+	for k := 1; n-2*k-1 >= nonceMinIndex; k++ {
+		ok := true
+		for _, publicKey := range args[n-2*k : n-k] {
+			if len(base58.Decode(publicKey)) != ed25519.PublicKeySize {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return k
+		}
+	}
+	return 0
+}
+
+// verifySignatures - single user must sign, multisig requires N valid signatures of policy
+func verifySignatures(sender *aclUser, publicKeys, sigs []string, message []byte) error {
+	if sender.policy == nil {
+		if len(publicKeys) != 1 || !ed25519.Verify(base58.Decode(publicKeys[0]), message, base58.Decode(sigs[0])) {
+			return errors.New("incorrect signature")
+		}
+		return nil
+	}
+
+	signed := 0
+	for i, sig := range sigs {
+		if sig == "" {
+			continue
+		}
+		if !ed25519.Verify(base58.Decode(publicKeys[i]), message, base58.Decode(sig)) {
+			return fmt.Errorf("incorrect signature of public key %s", publicKeys[i])
+		}
+		signed++
+	}
+	if signed < int(sender.policy.N) {
+		return fmt.Errorf("insufficient number of signatures %d, policy requires %d", signed, sender.policy.N)
+	}
+	return nil
+}
+
+// checkNonce - nonce must be greater than previous one if nonce ttl is zero.
+// Otherwise nonce must be unique and not older than nonce ttl relative to the latest nonce
+func (cc *tokenChaincode) checkNonce(address string, nonce int64) error {
+	nonces := cc.nonces[address]
+	if cc.nonceTTL == 0 {
+		if len(nonces) != 0 && nonce <= nonces[0] {
+			return fmt.Errorf("incorrect nonce %d, less or equal than last nonce %d", nonce, nonces[0])
+		}
+		cc.nonces[address] = []int64{nonce}
+		return nil
+	}
+
+	ttl := cc.nonceTTL.Milliseconds()
+	if len(nonces) != 0 {
+		last := nonces[len(nonces)-1]
+		if nonce <= last-ttl {
+			return fmt.Errorf("incorrect nonce %d, older than nonce ttl from last nonce %d", nonce, last)
+		}
+	}
+
+	i := sort.Search(len(nonces), func(i int) bool { return nonces[i] >= nonce })
+	if i < len(nonces) && nonces[i] == nonce {
+		return fmt.Errorf("incorrect nonce %d, already exists", nonce)
+	}
+	nonces = append(nonces[:i], append([]int64{nonce}, nonces[i:]...)...)
+
+	last := nonces[len(nonces)-1]
+	first := sort.Search(len(nonces), func(i int) bool { return nonces[i] > last-ttl })
+	cc.nonces[address] = nonces[first:]
+
+	return nil
+}
+
+// getBatchTxEvent - args: transaction id
+func (cc *tokenChaincode) getBatchTxEvent(args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected 1", len(args))
+	}
+
+	event, ok := cc.events[args[0]]
+	if !ok {
+		return nil, fmt.Errorf("batch event of transaction %s not found", args[0])
+	}
+	return proto.Marshal(event)
+}
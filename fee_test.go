@@ -0,0 +1,234 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fees"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// restoreFee - read fee config and fee address of fiat token and restore them in cleanup, transfers of other tests
+// expect fee they are started with. Token without fee gets zero fee, fee address can't be removed, so it is restored only if it was set
+func restoreFee(ctx context.Context, t provider.T, issuer *fixtures.Identity) error {
+	before, err := fees.Read(ctx, client, "fiat")
+	if err != nil {
+		return fmt.Errorf("read fee: %w", err)
+	}
+
+	currency := FiatName
+	fee, floor, feeCap := new(big.Int), new(big.Int), new(big.Int)
+	if config := before.FeeConfig(); config != nil {
+		if config.Currency != "" {
+			currency = config.Currency
+		}
+		fee, floor, feeCap = amount.FromBytes(config.Fee), amount.FromBytes(config.Floor), amount.FromBytes(config.Cap)
+	}
+
+	t.Cleanup(func() {
+		resp, err := fees.SetFee(ctx, client, issuer, "fiat", currency, fee, floor, feeCap)
+		if err == nil && before.FeeAddress() != "" {
+			_, err = fees.SetFeeAddress(ctx, client, issuer, "fiat", before.FeeAddress())
+		}
+		if err == nil {
+			err = client.WaitForTx(ctx, "fiat", resp.TransactionID, func(ctx context.Context) (*utils.Response, error) {
+				calc, err := fees.Read(ctx, client, "fiat")
+				if err != nil {
+					return nil, err
+				}
+				config := calc.FeeConfig()
+				if config == nil || !amount.Equal(fee, amount.FromBytes(config.Fee)) ||
+					!amount.Equal(floor, amount.FromBytes(config.Floor)) || !amount.Equal(feeCap, amount.FromBytes(config.Cap)) {
+					return nil, errors.New("fee isn't restored")
+				}
+				if before.FeeAddress() != "" && calc.FeeAddress() != before.FeeAddress() {
+					return nil, errors.New("fee address isn't restored")
+				}
+				return &utils.Response{}, nil
+			}, nil)
+		}
+		if err != nil {
+			t.Errorf("restore fee: %v", err)
+		}
+	})
+	return nil
+}
+
+// TestFeeTransfer - set fee of fiat token, transfer amounts hitting percentage, floor and cap, check fee is collected on fee address
+func TestFeeTransfer(t *testing.T) {
+	runner.Run(t, "transfer of `fiat` token with fee", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("Sender pays amount and fee, fee address collects fee limited by floor and cap")
+		t.Tags("positive", "fee")
+		requireBatchEvents(t)
+
+		var (
+			ctx = context.Background()
+
+			issuer, sender, recipient, collector *fixtures.Identity
+			calc                                 *fees.Calculator
+			balances                             fees.Balances
+		)
+
+		t.WithNewStep("Register users in acl", func(sCtx provider.StepCtx) {
+			var err error
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			sender, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			recipient, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			collector, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+		})
+
+		t.WithNewStep("Set fee 0.5% with floor 2 and cap 10 and fee address", func(sCtx provider.StepCtx) {
+			sCtx.Require().NoError(restoreFee(ctx, t, issuer))
+
+			resp, err := fees.SetFeeAddress(ctx, client, issuer, "fiat", collector.Address)
+			sCtx.Require().NoError(err)
+			utils.RequireTxSucceeded(ctx, sCtx, client, "fiat", resp.TransactionID)
+
+			resp, err = fees.SetFee(ctx, client, issuer, "fiat", FiatName, amount.New(500000), amount.New(2), amount.New(10))
+			sCtx.Require().NoError(err)
+			utils.RequireTxSucceeded(ctx, sCtx, client, "fiat", resp.TransactionID)
+
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				calc, err := fees.Read(ctx, client, "fiat")
+				if err != nil {
+					return nil, err
+				}
+				if calc.FeeAddress() != collector.Address || calc.FeeConfig() == nil ||
+					!amount.Equal(amount.New(500000), amount.FromBytes(calc.FeeConfig().Fee)) {
+					return nil, errors.New("fee isn't set")
+				}
+				return &utils.Response{}, nil
+			}, nil)
+			sCtx.Require().NoError(err)
+		})
+
+		t.WithNewStep("Read fee config from metadata", func(sCtx provider.StepCtx) {
+			var err error
+			calc, err = fees.Read(ctx, client, "fiat")
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Equal(collector.Address, calc.FeeAddress())
+			sCtx.Require().NotNil(calc.FeeConfig())
+			sCtx.Assert().True(amount.Equal(amount.New(500000), amount.FromBytes(calc.FeeConfig().Fee)))
+			sCtx.Assert().True(amount.Equal(amount.New(2), amount.FromBytes(calc.FeeConfig().Floor)))
+			sCtx.Assert().True(amount.Equal(amount.New(10), amount.FromBytes(calc.FeeConfig().Cap)))
+		})
+
+		t.WithNewStep("Emit FIAT token to sender", func(sCtx provider.StepCtx) {
+			signedArgs, err := issuer.Sign("fiat", "fiat", "emit", sender.Address, "100000")
+			sCtx.Require().NoError(err)
+			resp, err := client.Invoke(ctx, "fiat", "emit", signedArgs...)
+			sCtx.Require().NoError(err)
+			utils.RequireTxSucceeded(ctx, sCtx, client, "fiat", resp.TransactionID)
+			requireBalance(ctx, sCtx, "fiat", sender.Address, amount.New(100000))
+			balances = fees.Balances{sender.Address: amount.New(100000)}
+		})
+
+		for _, tc := range []struct {
+			name  string
+			value int64
+			fee   int64
+		}{
+			{"fee is percentage of amount", 1000, 5},
+			{"fee is raised to floor", 100, 2},
+			{"fee is limited by cap", 10000, 10},
+		} {
+			t.WithNewStep("Transfer with fee: "+tc.name, func(sCtx provider.StepCtx) {
+				fee, err := calc.Fee(amount.New(tc.value))
+				sCtx.Require().NoError(err)
+				sCtx.Assert().True(amount.Equal(amount.New(tc.fee), fee))
+
+				expected, err := calc.Transfer(balances, sender.Address, recipient.Address, amount.New(tc.value))
+				sCtx.Require().NoError(err)
+
+				signedArgs, err := sender.Sign("fiat", "fiat", "transfer", recipient.Address, amount.String(amount.New(tc.value)), "")
+				sCtx.Require().NoError(err)
+				resp, err := client.Invoke(ctx, "fiat", "transfer", signedArgs...)
+				sCtx.Require().NoError(err)
+				utils.RequireTxSucceeded(ctx, sCtx, client, "fiat", resp.TransactionID)
+
+				for _, address := range []string{sender.Address, recipient.Address, collector.Address} {
+					requireBalance(ctx, sCtx, "fiat", address, expected[address])
+				}
+				balances = expected
+			})
+		}
+
+		t.WithNewStep("Fee address collected fees of all transfers", func(sCtx provider.StepCtx) {
+			balance, err := client.BalanceOf(ctx, "fiat", collector.Address)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(amount.Equal(amount.New(17), balance))
+		})
+
+		t.WithNewStep("Transfer of whole balance fails because sender can't pay fee", func(sCtx provider.StepCtx) {
+			value := balances[sender.Address]
+			_, err := calc.Transfer(balances, sender.Address, recipient.Address, value)
+			sCtx.Assert().True(errors.Is(err, utils.ErrInsufficientFunds))
+
+			signedArgs, err := sender.Sign("fiat", "fiat", "transfer", recipient.Address, amount.String(value), "")
+			sCtx.Require().NoError(err)
+			resp, err := client.Invoke(ctx, "fiat", "transfer", signedArgs...)
+			sCtx.Require().NoError(err)
+			utils.RequireTxFailedWith(ctx, sCtx, client, "fiat", resp.TransactionID, http.StatusInternalServerError, utils.ErrInsufficientFunds)
+
+			balance, err := client.BalanceOf(ctx, "fiat", sender.Address)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(amount.Equal(value, balance))
+		})
+	})
+}
+
+// TestSetFeeNegative - setFee is rejected for user without rights and for incorrect fee and limits, fee config isn't changed
+func TestSetFeeNegative(t *testing.T) {
+	runner.Run(t, "incorrect setFee of `fiat` token", func(t provider.T) {
+		t.Severity(allure.NORMAL)
+		t.Description("setFee fails in batch for user other than fee setter, fee greater than 100% and floor greater than cap")
+		t.Tags("negative", "fee")
+		requireBatchEvents(t)
+
+		ctx := context.Background()
+
+		issuer, err := fixtures.NewIssuer(ctx, client)
+		t.Require().NoError(err)
+		user, err := fixtures.NewUser(ctx, client)
+		t.Require().NoError(err)
+		t.Require().NoError(restoreFee(ctx, t, issuer))
+		before, err := fees.Read(ctx, client, "fiat")
+		t.Require().NoError(err)
+
+		for _, tc := range []struct {
+			name              string
+			setter            *fixtures.Identity
+			fee, floor, limit int64
+			target            error
+		}{
+			{"user isn't fee setter", user, 500000, 0, 0, utils.ErrAccessDenied},
+			{"fee is greater than 100%", issuer, 100000001, 0, 0, nil},
+			{"floor is greater than cap", issuer, 500000, 10, 5, nil},
+		} {
+			t.WithNewStep("setFee fails: "+tc.name, func(sCtx provider.StepCtx) {
+				resp, err := fees.SetFee(ctx, client, tc.setter, "fiat", FiatName, amount.New(tc.fee), amount.New(tc.floor), amount.New(tc.limit))
+				sCtx.Require().NoError(err)
+				utils.RequireTxFailedWith(ctx, sCtx, client, "fiat", resp.TransactionID, http.StatusInternalServerError, tc.target)
+
+				after, err := fees.Read(ctx, client, "fiat")
+				sCtx.Require().NoError(err)
+				sCtx.Assert().True(proto.Equal(before.FeeConfig(), after.FeeConfig()), "fee config is changed: %v", after.FeeConfig())
+			})
+		}
+	})
+}
@@ -0,0 +1,154 @@
+// Package fees - fee of transfers of token chaincode built on foundation library.
+// Fee is percentage of amount with Decimals limited by floor and cap, it is paid by sender to fee address
+// in addition to amount. Calculator reads fee config from metadata and predicts balances after transfer
+package fees
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+const (
+	// SetFeeFn - signed method to set fee: currency, fee, floor, cap
+	SetFeeFn = "setFee"
+	// SetFeeAddressFn - signed method to set address receiving fee
+	SetFeeAddressFn = "setFeeAddress"
+	// Decimals - decimals of fee percentage, 100000000 is 100%
+	Decimals = 8
+)
+
+// ErrFeeAddressNotSet - token has fee but no address to receive it
+var ErrFeeAddressNotSet = errors.New("fee address isn't set")
+
+// Balances - balances of token by address
+type Balances map[string]*big.Int
+
+// Calculator - fee of transfers of token
+type Calculator struct {
+	symbol     string
+	fee        *pb.TokenFee
+	feeAddress string
+}
+
+// NewCalculator - calculator of token symbol with fee config, nil fee means transfers without fee
+func NewCalculator(symbol string, fee *pb.TokenFee, feeAddress []byte) (*Calculator, error) {
+	address, err := utils.EncodeAddress(feeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("fee address: %w", err)
+	}
+	return &Calculator{
+		symbol:     strings.ToUpper(symbol),
+		fee:        fee,
+		feeAddress: address,
+	}, nil
+}
+
+// FromToken - calculator of plain token
+func FromToken(symbol string, token *pb.Token) (*Calculator, error) {
+	return NewCalculator(symbol, token.GetFee(), token.GetFeeAddress())
+}
+
+// FromIndustrial - calculator of industrial token
+func FromIndustrial(symbol string, industrial *pb.Industrial) (*Calculator, error) {
+	return NewCalculator(symbol, industrial.GetFee(), industrial.GetFeeAddress())
+}
+
+// Read - calculator of token from metadata of channel, see utils.Client.TokenConfig
+func Read(ctx context.Context, client *utils.Client, channel string) (*Calculator, error) {
+	symbol, token, err := client.TokenConfig(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	return FromToken(symbol, token)
+}
+
+// FeeConfig - fee config of token, nil if transfers have no fee
+func (c *Calculator) FeeConfig() *pb.TokenFee {
+	return c.fee
+}
+
+// FeeAddress - address receiving fee in base58 check, empty if it isn't set
+func (c *Calculator) FeeAddress() string {
+	return c.feeAddress
+}
+
+// Fee - fee of transfer of value: value * fee / 10^Decimals, not greater than cap if cap is positive
+// and not less than floor. Fee in currency other than token requires rates and isn't supported
+func (c *Calculator) Fee(value *big.Int) (*big.Int, error) {
+	if c.fee == nil || c.fee.Currency == "" {
+		return new(big.Int), nil
+	}
+	if !strings.EqualFold(c.fee.Currency, c.symbol) {
+		return nil, fmt.Errorf("fee in currency %s of token %s isn't supported", c.fee.Currency, c.symbol)
+	}
+
+	fee := amount.Mul(value, amount.FromBytes(c.fee.Fee))
+	fee.Div(fee, hundredPercent())
+	if feeCap := amount.FromBytes(c.fee.Cap); feeCap.Sign() > 0 && fee.Cmp(feeCap) > 0 {
+		fee = feeCap
+	}
+	if floor := amount.FromBytes(c.fee.Floor); fee.Cmp(floor) < 0 {
+		fee = floor
+	}
+	return fee, nil
+}
+
+// Transfer - balances after transfer of value from sender to recipient: sender pays value and fee,
+// fee address receives fee. Balances isn't modified, missing address has zero balance
+func (c *Calculator) Transfer(balances Balances, sender, recipient string, value *big.Int) (Balances, error) {
+	fee, err := c.Fee(value)
+	if err != nil {
+		return nil, err
+	}
+	if fee.Sign() > 0 && c.feeAddress == "" {
+		return nil, ErrFeeAddressNotSet
+	}
+
+	total := amount.Add(value, fee)
+	if amount.Cmp(balances[sender], total) < 0 {
+		return nil, fmt.Errorf("balance %s of %s, amount %s, fee %s: %w",
+			amount.String(balances[sender]), sender, amount.String(value), amount.String(fee), utils.ErrInsufficientFunds)
+	}
+
+	result := make(Balances, len(balances)+2)
+	for address, balance := range balances {
+		result[address] = amount.Add(balance)
+	}
+	result[sender] = amount.Sub(result[sender], total)
+	result[recipient] = amount.Add(result[recipient], value)
+	if fee.Sign() > 0 {
+		result[c.feeAddress] = amount.Add(result[c.feeAddress], fee)
+	}
+	return result, nil
+}
+
+// SetFee - invoke setFee in channel signed by setter, zero cap means no upper limit
+func SetFee(ctx context.Context, client *utils.Client, setter *fixtures.Identity, channel, currency string, fee, floor, feeCap *big.Int) (*utils.Response, error) {
+	args, err := setter.Sign(channel, channel, SetFeeFn,
+		strings.ToUpper(currency), amount.String(fee), amount.String(floor), amount.String(feeCap))
+	if err != nil {
+		return nil, err
+	}
+	return client.Invoke(ctx, channel, SetFeeFn, args...)
+}
+
+// SetFeeAddress - invoke setFeeAddress in channel signed by setter
+func SetFeeAddress(ctx context.Context, client *utils.Client, setter *fixtures.Identity, channel, address string) (*utils.Response, error) {
+	args, err := setter.Sign(channel, channel, SetFeeAddressFn, address)
+	if err != nil {
+		return nil, err
+	}
+	return client.Invoke(ctx, channel, SetFeeAddressFn, args...)
+}
+
+func hundredPercent() *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(Decimals), nil)
+}
@@ -0,0 +1,83 @@
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+	"golang.org/x/crypto/ed25519"
+)
+
+// Multisig - multisig wallet registered and confirmed in acl chaincode
+type Multisig struct {
+	Policy  *pb.SignaturePolicy
+	Members []*Identity
+	// Address - address in base58 check, used by token chaincodes
+	Address string
+}
+
+// Sign - sign arguments of method by members of multisig, every signer must be member.
+// Nonce is taken from utils.DefaultNonceSource by joined public keys of policy
+func (m *Multisig) Sign(signers []*Identity, channel, chaincode, method string, args ...string) ([]string, error) {
+	keySigners, err := Signers(signers...)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := utils.SignMultisigTx(m.Policy, keySigners, channel, chaincode, method, m.nextNonce(), args...)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Args(), nil
+}
+
+func (m *Multisig) nextNonce() string {
+	return utils.DefaultNonceSource.Next(ed25519.PublicKey(bytes.Join(m.Policy.PubKeys, nil)))
+}
+
+// NewMultisig - add N-of-M multisig of members to acl chaincode and wait until it is confirmed by checkKeys.
+// All members sign registration, members don't have to be registered as users
+func NewMultisig(ctx context.Context, client *utils.Client, n int, members ...*Identity) (*Multisig, error) {
+	publicKeys := make([]ed25519.PublicKey, len(members))
+	for i, member := range members {
+		publicKeys[i] = member.PublicKey
+	}
+	policy, err := utils.NewSignaturePolicy(n, publicKeys...)
+	if err != nil {
+		return nil, fmt.Errorf("signature policy: %w", err)
+	}
+
+	address, err := utils.GetMultisigAddress(policy)
+	if err != nil {
+		return nil, fmt.Errorf("get address: %w", err)
+	}
+	multisig := &Multisig{
+		Policy:  policy,
+		Members: members,
+		Address: address,
+	}
+
+	signers, err := Signers(members...)
+	if err != nil {
+		return nil, err
+	}
+	args, err := utils.SignAddMultisig(policy, signers, multisig.nextNonce())
+	if err != nil {
+		return nil, fmt.Errorf("sign add multisig: %w", err)
+	}
+	_, err = client.Invoke(ctx, aclChaincode, utils.AddMultisigFn, args...)
+	if err != nil && !errors.Is(err, utils.ErrUserAlreadyExists) {
+		return nil, fmt.Errorf("add multisig: %w", err)
+	}
+
+	_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+		return client.Query(ctx, aclChaincode, "checkKeys", utils.MultisigKey(policy))
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("check keys: %w", err)
+	}
+
+	return multisig, nil
+}
@@ -0,0 +1,96 @@
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/tickets-dao/integration/acl"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// ChangeKey - replace key of identity by new generated key signed by validators and wait until it is confirmed
+// by checkKeys. Returned identity has the same address, key of old identity can't sign anymore
+func ChangeKey(ctx context.Context, client *utils.Client, validators []utils.Signer, identity *Identity, reason string, reasonID int32) (*Identity, error) {
+	rotated, err := newKey(identity.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = acl.NewClient(client).ChangePublicKey(ctx, validators, identity.Address, reason, reasonID, rotated.PublicKey); err != nil {
+		return nil, fmt.Errorf("change public key: %w", err)
+	}
+	if err = waitKeys(ctx, client, rotated.PublicKeyBase58); err != nil {
+		return nil, err
+	}
+	return rotated, nil
+}
+
+// ReplaceMember - replace key of multisig member by new generated key signed by validators and wait until
+// new keys of multisig are confirmed by checkKeys. Policy and members of multisig are updated, address doesn't change
+func (m *Multisig) ReplaceMember(ctx context.Context, client *utils.Client, validators []utils.Signer, member *Identity, reason string, reasonID int32) (*Identity, error) {
+	i := -1
+	for j, candidate := range m.Members {
+		if bytes.Equal(candidate.PublicKey, member.PublicKey) {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("public key %s isn't member of multisig", member.PublicKeyBase58)
+	}
+
+	rotated, err := newKey("")
+	if err != nil {
+		return nil, err
+	}
+	if rotated.Address, err = utils.GetAddressByPublicKey(rotated.PublicKey); err != nil {
+		return nil, fmt.Errorf("get address: %w", err)
+	}
+
+	_, err = acl.NewClient(client).ChangeMultisigPublicKey(ctx, validators, m.Address, member.PublicKey, rotated.PublicKey, reason, reasonID)
+	if err != nil {
+		return nil, fmt.Errorf("change multisig public key: %w", err)
+	}
+
+	keys := make([][]byte, 0, len(m.Policy.PubKeys))
+	for _, key := range m.Policy.PubKeys {
+		if bytes.Equal(key, member.PublicKey) {
+			key = rotated.PublicKey
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	m.Policy.PubKeys = keys
+	m.Members[i] = rotated
+
+	if err = waitKeys(ctx, client, utils.MultisigKey(m.Policy)); err != nil {
+		return nil, err
+	}
+	return rotated, nil
+}
+
+// newKey - identity with generated key and given address
+func newKey(address string) (*Identity, error) {
+	privateKey, publicKey, err := utils.GeneratePrivateAndPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+	return &Identity{
+		PrivateKey:      privateKey,
+		PublicKey:       publicKey,
+		PublicKeyBase58: utils.ConvertPublicKeyToBase58(publicKey),
+		Address:         address,
+	}, nil
+}
+
+func waitKeys(ctx context.Context, client *utils.Client, publicKey string) error {
+	_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+		return client.Query(ctx, aclChaincode, acl.CheckKeysFn, publicKey)
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("check keys: %w", err)
+	}
+	return nil
+}
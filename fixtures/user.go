@@ -0,0 +1,212 @@
+// Package fixtures - registered test identities
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/tickets-dao/integration/keystore"
+	"github.com/tickets-dao/integration/utils"
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	aclChaincode = "acl"
+
+	// DefaultKYCHash - kyc hash of users created by tests
+	DefaultKYCHash = "test"
+	// DefaultUserID - user id of users created by tests
+	DefaultUserID = "testuser"
+)
+
+// Identity - user registered and confirmed in acl chaincode
+type Identity struct {
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+	// PublicKeyBase58 - public key in base58, used by acl chaincode
+	PublicKeyBase58 string
+	// Address - address in base58 check, used by token chaincodes
+	Address string
+}
+
+// Sign - sign arguments of method by identity, see utils.Sign
+func (i *Identity) Sign(channel, chaincode, method string, args ...string) ([]string, error) {
+	return utils.Sign(i.PrivateKey, i.PublicKey, channel, chaincode, method, args)
+}
+
+// SignWithNonce - sign arguments of method by identity with explicit nonce, see utils.SignWithNonce
+func (i *Identity) SignWithNonce(nonce, channel, chaincode, method string, args ...string) ([]string, error) {
+	return utils.SignWithNonce(i.PrivateKey, i.PublicKey, channel, chaincode, method, args, nonce)
+}
+
+// Signer - signer with key of identity, see utils.SignTx
+func (i *Identity) Signer() (utils.Signer, error) {
+	signer, err := utils.NewKeySigner(i.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("signer of %s: %w", i.PublicKeyBase58, err)
+	}
+	return signer, nil
+}
+
+// Signers - signers with keys of identities, see Identity.Signer
+func Signers(identities ...*Identity) ([]utils.Signer, error) {
+	signers := make([]utils.Signer, len(identities))
+	for i, identity := range identities {
+		signer, err := identity.Signer()
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = signer
+	}
+	return signers, nil
+}
+
+type options struct {
+	kycHash      string
+	userID       string
+	isIndustrial bool
+	privateKey   ed25519.PrivateKey
+	publicKey    ed25519.PublicKey
+}
+
+// Option - configures NewUser
+type Option func(o *options) error
+
+// WithKYCHash - kyc hash of user, DefaultKYCHash by default
+func WithKYCHash(kycHash string) Option {
+	return func(o *options) error {
+		o.kycHash = kycHash
+		return nil
+	}
+}
+
+// WithUserID - user id of user, DefaultUserID by default
+func WithUserID(userID string) Option {
+	return func(o *options) error {
+		o.userID = userID
+		return nil
+	}
+}
+
+// WithIndustrial - industrial flag of user, true by default
+func WithIndustrial(isIndustrial bool) Option {
+	return func(o *options) error {
+		o.isIndustrial = isIndustrial
+		return nil
+	}
+}
+
+// WithPrivateKey - register existing key instead of generating new one
+func WithPrivateKey(privateKey ed25519.PrivateKey) Option {
+	return func(o *options) error {
+		publicKey, ok := privateKey.Public().(ed25519.PublicKey)
+		if !ok {
+			return errors.New("type assertion failed")
+		}
+		o.privateKey, o.publicKey = privateKey, publicKey
+		return nil
+	}
+}
+
+// WithDerivedKey - register key derived by deriver from path like 'test/transfer/userFrom',
+// the same seed and path give the same address on every run
+func WithDerivedKey(deriver *utils.KeyDeriver, path string) Option {
+	return func(o *options) error {
+		privateKey, publicKey, err := deriver.Derive(path)
+		if err != nil {
+			return fmt.Errorf("derive key %s: %w", path, err)
+		}
+		o.privateKey, o.publicKey = privateKey, publicKey
+		return nil
+	}
+}
+
+// FromKeystore - register key with the name from keystore
+func FromKeystore(ks *keystore.Keystore, name string) Option {
+	return func(o *options) error {
+		privateKey, err := ks.Get(name)
+		if err != nil {
+			return err
+		}
+		return WithPrivateKey(privateKey)(o)
+	}
+}
+
+// FromIssuerEnv - register issuer with key from utils.EnvFiatIssuerPrivateKey,
+// key keystore.IssuerKey of keystore.FromEnv is used if it isn't set
+func FromIssuerEnv() Option {
+	return func(o *options) error {
+		if os.Getenv(utils.EnvFiatIssuerPrivateKey) == "" {
+			ks, err := keystore.FromEnv()
+			if err != nil {
+				return fmt.Errorf("issuer keystore: %w", err)
+			}
+			if ks != nil {
+				return FromKeystore(ks, keystore.IssuerKey)(o)
+			}
+		}
+
+		privateKey, publicKey, err := utils.GetPrivateKeyFromBase58Check(os.Getenv(utils.EnvFiatIssuerPrivateKey))
+		if err != nil {
+			return fmt.Errorf("issuer private key from %s: %w", utils.EnvFiatIssuerPrivateKey, err)
+		}
+		o.privateKey, o.publicKey = privateKey, publicKey
+		return nil
+	}
+}
+
+// NewUser - add user to acl chaincode and wait until it is confirmed by checkKeys.
+// Existing user is not an error, so NewUser can be called for the same key many times
+func NewUser(ctx context.Context, client *utils.Client, opts ...Option) (*Identity, error) {
+	o := &options{
+		kycHash:      DefaultKYCHash,
+		userID:       DefaultUserID,
+		isIndustrial: true,
+	}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.privateKey == nil {
+		var err error
+		if o.privateKey, o.publicKey, err = utils.GeneratePrivateAndPublicKey(); err != nil {
+			return nil, fmt.Errorf("generate key: %w", err)
+		}
+	}
+
+	address, err := utils.GetAddressByPublicKey(o.publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("get address: %w", err)
+	}
+	identity := &Identity{
+		PrivateKey:      o.privateKey,
+		PublicKey:       o.publicKey,
+		PublicKeyBase58: utils.ConvertPublicKeyToBase58(o.publicKey),
+		Address:         address,
+	}
+
+	_, err = client.Invoke(ctx, aclChaincode, "addUser",
+		identity.PublicKeyBase58, o.kycHash, o.userID, strconv.FormatBool(o.isIndustrial))
+	if err != nil && !errors.Is(err, utils.ErrUserAlreadyExists) {
+		return nil, fmt.Errorf("add user: %w", err)
+	}
+
+	_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+		return client.Query(ctx, aclChaincode, "checkKeys", identity.PublicKeyBase58)
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("check keys: %w", err)
+	}
+
+	return identity, nil
+}
+
+// NewIssuer - register issuer with key from utils.EnvFiatIssuerPrivateKey, see NewUser
+func NewIssuer(ctx context.Context, client *utils.Client, opts ...Option) (*Identity, error) {
+	return NewUser(ctx, client, append([]Option{FromIssuerEnv()}, opts...)...)
+}
@@ -0,0 +1,126 @@
+// Package industrial - industrial token chaincode built on foundation library.
+// Industrial token is emitted by groups, every group has emission, maturity and note. Issuer receives emission
+// of all groups by initialize, owners transfer tokens of group and redeem them after maturity of group
+package industrial
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+const (
+	// InitializeFn - signed method of issuer to emit all groups to issuer, repeated call does nothing
+	InitializeFn = "initialize"
+	// TransferFn - signed method to transfer tokens of group: address, group, amount, reference
+	TransferFn = "transferIndustrial"
+	// RedeemFn - signed method to burn tokens of matured group: group, amount, reference
+	RedeemFn = "redeem"
+)
+
+// Token - config of industrial token with symbol
+type Token struct {
+	Symbol string
+	*pb.Industrial
+}
+
+// Read - config of industrial token from metadata of channel, see utils.Client.IndustrialConfig
+func Read(ctx context.Context, client *utils.Client, channel string) (*Token, error) {
+	symbol, industrial, err := client.IndustrialConfig(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	return &Token{Symbol: symbol, Industrial: industrial}, nil
+}
+
+// Group - group of token by id, id may be prefixed with symbol like INDUSTRIAL_202010
+func (t *Token) Group(id string) (*pb.IndustrialGroup, bool) {
+	id = strings.TrimPrefix(id, strings.ToUpper(t.Symbol)+"_")
+	for _, group := range t.Groups {
+		if group.Id == id {
+			return group, true
+		}
+	}
+	return nil, false
+}
+
+// Matured - groups of token matured at the moment, in order of metadata
+func (t *Token) Matured(now time.Time) []*pb.IndustrialGroup {
+	var groups []*pb.IndustrialGroup
+	for _, group := range t.Groups {
+		if Matured(group, now) {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// Key - token key of group in balances and accounting records, example INDUSTRIAL_202010
+func (t *Token) Key(group string) string {
+	return strings.ToUpper(t.Symbol) + "_" + group
+}
+
+// Maturity - maturity of group
+func Maturity(group *pb.IndustrialGroup) time.Time {
+	return time.Unix(group.Maturity, 0).UTC()
+}
+
+// Matured - report whether group can be redeemed at the moment
+func Matured(group *pb.IndustrialGroup, now time.Time) bool {
+	return !now.Before(Maturity(group))
+}
+
+// CheckRedeem - group must be matured at the moment and balance must cover value,
+// error is utils.ErrGroupNotMatured or utils.ErrInsufficientFunds otherwise
+func CheckRedeem(group *pb.IndustrialGroup, now time.Time, balance, value *big.Int) error {
+	if !Matured(group, now) {
+		return fmt.Errorf("group %s, maturity %s: %w", group.Id, Maturity(group).Format(time.RFC3339), utils.ErrGroupNotMatured)
+	}
+	if amount.Cmp(balance, value) < 0 {
+		return fmt.Errorf("group %s: balance %s, amount %s: %w",
+			group.Id, amount.String(balance), amount.String(value), utils.ErrInsufficientFunds)
+	}
+	return nil
+}
+
+// BalanceOf - balance of address in group, zero if address has no tokens of group
+func BalanceOf(ctx context.Context, client *utils.Client, channel, address, group string) (*big.Int, error) {
+	balances, err := client.IndustrialBalanceOf(ctx, channel, address)
+	if err != nil {
+		return nil, err
+	}
+	if balance, ok := balances[group]; ok {
+		return balance, nil
+	}
+	return amount.New(0), nil
+}
+
+// Initialize - invoke initialize in channel signed by issuer
+func Initialize(ctx context.Context, client *utils.Client, issuer *fixtures.Identity, channel string) (*utils.Response, error) {
+	return invoke(ctx, client, issuer, channel, InitializeFn)
+}
+
+// Transfer - invoke transferIndustrial of group in channel signed by sender
+func Transfer(ctx context.Context, client *utils.Client, sender *fixtures.Identity, channel, to, group string, value *big.Int, reference string) (*utils.Response, error) {
+	return invoke(ctx, client, sender, channel, TransferFn, to, group, amount.String(value), reference)
+}
+
+// Redeem - invoke redeem of group in channel signed by owner
+func Redeem(ctx context.Context, client *utils.Client, owner *fixtures.Identity, channel, group string, value *big.Int, reference string) (*utils.Response, error) {
+	return invoke(ctx, client, owner, channel, RedeemFn, group, amount.String(value), reference)
+}
+
+func invoke(ctx context.Context, client *utils.Client, signer *fixtures.Identity, channel, method string, args ...string) (*utils.Response, error) {
+	signedArgs, err := signer.Sign(channel, channel, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return client.Invoke(ctx, channel, method, signedArgs...)
+}
@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/industrial"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// TestIndustrialLifecycle - initialize industrial token, transfer every group to owner and redeem matured groups
+func TestIndustrialLifecycle(t *testing.T) {
+	runner.Run(t, "lifecycle of `industrial` token groups", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("Issuer initializes all groups and transfers them, owner redeems groups after maturity only")
+		t.Tags("positive", "negative", "industrial")
+		requireBatchEvents(t)
+
+		var (
+			ctx = context.Background()
+
+			issuer, owner *fixtures.Identity
+			token         *industrial.Token
+		)
+
+		// requireGroupBalance - wait until balance of group of owner is equal to expected
+		requireGroupBalance := func(sCtx provider.StepCtx, group string, expected *big.Int) {
+			_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, itSymbol, utils.IndustrialBalanceOfFn, owner.Address)
+			}, utils.IndustrialAmountEquals(group, expected))
+			sCtx.Require().NoError(err, "balance of group %s: expected %s", group, amount.String(expected))
+		}
+
+		t.WithNewStep("Register users in acl", func(sCtx provider.StepCtx) {
+			var err error
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			owner, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+		})
+
+		t.WithNewStep("Initialize industrial token and read groups from metadata", func(sCtx provider.StepCtx) {
+			resp, err := industrial.Initialize(ctx, client, issuer, itSymbol)
+			sCtx.Require().NoError(err)
+			utils.RequireTxSucceeded(ctx, sCtx, client, itSymbol, resp.TransactionID)
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, itSymbol, utils.IndustrialBalanceOfFn, issuer.Address)
+			}, utils.IndustrialIssued())
+			sCtx.Require().NoError(err)
+
+			token, err = industrial.Read(ctx, client, itSymbol)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(token.Initialized)
+			sCtx.Require().NotEmpty(token.Groups)
+			for _, group := range token.Groups {
+				sCtx.Logf("group %s: emission %s, maturity %s, note %q",
+					group.Id, amount.FromBytes(group.Emission), industrial.Maturity(group).Format(time.RFC3339), group.Note)
+				sCtx.Assert().True(amount.FromBytes(group.Emission).Sign() > 0)
+			}
+		})
+
+		for _, group := range token.Groups {
+			t.WithNewStep("Transfer group to owner: "+group.Id, func(sCtx provider.StepCtx) {
+				resp, err := industrial.Transfer(ctx, client, issuer, itSymbol, owner.Address, group.Id, amount.New(5), "")
+				sCtx.Require().NoError(err)
+				utils.RequireTxSucceeded(ctx, sCtx, client, itSymbol, resp.TransactionID)
+				requireGroupBalance(sCtx, group.Id, amount.New(5))
+			})
+		}
+
+		t.WithNewStep("Owner redeems part of matured groups", func(sCtx provider.StepCtx) {
+			matured := token.Matured(time.Now())
+			sCtx.Require().NotEmpty(matured)
+			for _, group := range matured {
+				before, err := industrial.BalanceOf(ctx, client, itSymbol, owner.Address, group.Id)
+				sCtx.Require().NoError(err)
+				if before.Sign() == 0 {
+					continue
+				}
+				sCtx.Require().NoError(industrial.CheckRedeem(group, time.Now(), before, amount.New(2)))
+
+				resp, err := industrial.Redeem(ctx, client, owner, itSymbol, group.Id, amount.New(2), "")
+				sCtx.Require().NoError(err)
+				utils.RequireTxSucceeded(ctx, sCtx, client, itSymbol, resp.TransactionID)
+				requireGroupBalance(sCtx, group.Id, amount.Sub(before, amount.New(2)))
+			}
+		})
+
+		var unmatured *pb.IndustrialGroup
+		for _, group := range token.Groups {
+			if !industrial.Matured(group, time.Now()) {
+				unmatured = group
+				break
+			}
+		}
+		if unmatured == nil {
+			t.Logf("redeem before maturity isn't checked: every group of %s is matured", itSymbol)
+		} else {
+			t.WithNewStep("Redeem of group before maturity is rejected: "+unmatured.Id, func(sCtx provider.StepCtx) {
+				err := industrial.CheckRedeem(unmatured, time.Now(), amount.New(5), amount.New(1))
+				sCtx.Assert().True(errors.Is(err, utils.ErrGroupNotMatured))
+
+				resp, err := industrial.Redeem(ctx, client, owner, itSymbol, unmatured.Id, amount.New(1), "")
+				sCtx.Require().NoError(err)
+				utils.RequireTxFailedWith(ctx, sCtx, client, itSymbol, resp.TransactionID, http.StatusInternalServerError, utils.ErrGroupNotMatured)
+
+				balance, err := industrial.BalanceOf(ctx, client, itSymbol, owner.Address, unmatured.Id)
+				sCtx.Require().NoError(err)
+				sCtx.Assert().True(amount.Equal(amount.New(5), balance))
+			})
+		}
+
+		t.WithNewStep("Redeem of more than balance is rejected", func(sCtx provider.StepCtx) {
+			matured := token.Matured(time.Now())
+			sCtx.Require().NotEmpty(matured)
+			group := matured[0]
+			balance, err := industrial.BalanceOf(ctx, client, itSymbol, owner.Address, group.Id)
+			sCtx.Require().NoError(err)
+			value := amount.Add(balance, amount.New(1))
+
+			err = industrial.CheckRedeem(group, time.Now(), balance, value)
+			sCtx.Assert().True(errors.Is(err, utils.ErrInsufficientFunds))
+
+			resp, err := industrial.Redeem(ctx, client, owner, itSymbol, group.Id, value, "")
+			sCtx.Require().NoError(err)
+			utils.RequireTxFailedWith(ctx, sCtx, client, itSymbol, resp.TransactionID, http.StatusInternalServerError, utils.ErrInsufficientFunds)
+			requireGroupBalance(sCtx, group.Id, balance)
+		})
+
+		t.WithNewStep("Transfer of unknown group is rejected", func(sCtx provider.StepCtx) {
+			resp, err := industrial.Transfer(ctx, client, issuer, itSymbol, owner.Address, "199901", amount.New(1), "")
+			sCtx.Require().NoError(err)
+			utils.RequireTxFailedWith(ctx, sCtx, client, itSymbol, resp.TransactionID, http.StatusInternalServerError, nil)
+
+			balances, err := client.IndustrialBalanceOf(ctx, itSymbol, owner.Address)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().NotContains(balances, "199901")
+		})
+	})
+}
@@ -0,0 +1,363 @@
+// Package keystore - named ed25519 keys of long-lived test identities in file encrypted by passphrase.
+// Key of encryption is derived from passphrase by scrypt, keys are sealed by XChaCha20-Poly1305,
+// so issuer, validator and fee collector keys can be kept across environments without secrets in env
+package keystore
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/tickets-dao/integration/utils"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// EnvKeystorePath - file of keystore, keystore isn't used if it is empty
+	EnvKeystorePath = "KEYSTORE_PATH"
+	// EnvKeystorePassphrase - passphrase of keystore file
+	EnvKeystorePassphrase = "KEYSTORE_PASSPHRASE" //nolint:gosec
+)
+
+// IssuerKey - name of key of fiat issuer, used by fixtures.FromIssuerEnv if utils.EnvFiatIssuerPrivateKey isn't set
+const IssuerKey = "issuer"
+
+const (
+	version = 1
+	kdf     = "scrypt"
+
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	saltLen      = 32
+	fileMode     = 0o600
+	dirMode      = 0o700
+	tmpExtension = ".tmp"
+)
+
+var (
+	// ErrKeyNotFound - keystore has no key with the name
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrDecrypt - passphrase is wrong or file is corrupted
+	ErrDecrypt = errors.New("decrypt keystore: wrong passphrase or corrupted file")
+	// ErrUnsupportedKDF - scrypt parameters or salt of file differ from ones written by Save
+	ErrUnsupportedKDF = errors.New("unsupported kdf parameters")
+)
+
+// Entry - public part of key in keystore
+type Entry struct {
+	Name string
+	// PublicKeyBase58 - public key in base58, used by acl chaincode
+	PublicKeyBase58 string
+	// Address - address in base58 check, used by token chaincodes
+	Address string
+}
+
+// Keystore - named private keys, changes are written to file by Save
+type Keystore struct {
+	mu         sync.RWMutex
+	path       string
+	passphrase []byte
+	keys       map[string]ed25519.PrivateKey
+}
+
+// file - encrypted keystore on disk, header is authenticated as additional data of AEAD
+type file struct {
+	Version int       `json:"version"`
+	KDF     kdfConfig `json:"kdf"`
+	// Nonce - nonce of XChaCha20-Poly1305
+	Nonce []byte `json:"nonce"`
+	// Ciphertext - sealed json object of names and private keys in base58 check
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type kdfConfig struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+// New - empty keystore which is written to path by Save
+func New(path, passphrase string) *Keystore {
+	return &Keystore{
+		path:       path,
+		passphrase: []byte(passphrase),
+		keys:       make(map[string]ed25519.PrivateKey),
+	}
+}
+
+// Open - read and decrypt keystore file, ErrDecrypt is returned for wrong passphrase
+// and ErrUnsupportedKDF for kdf parameters other than written by Save
+func Open(path, passphrase string) (*Keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore: %w", err)
+	}
+
+	f := &file{}
+	if err = json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("json unmarshal: %w", err)
+	}
+	if f.Version != version || f.KDF.Name != kdf {
+		return nil, fmt.Errorf("unsupported keystore version %d with kdf %s", f.Version, f.KDF.Name)
+	}
+	if err = f.KDF.validate(); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD([]byte(passphrase), f.KDF)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := f.additionalData()
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("incorrect nonce length %d", len(f.Nonce))
+	}
+	plaintext, err := aead.Open(nil, f.Nonce, f.Ciphertext, ad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	encoded := make(map[string]string)
+	if err = json.Unmarshal(plaintext, &encoded); err != nil {
+		return nil, fmt.Errorf("json unmarshal keys: %w", err)
+	}
+
+	ks := New(path, passphrase)
+	for name, secret := range encoded {
+		privateKey, _, err := utils.GetPrivateKeyFromBase58Check(secret)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", name, err)
+		}
+		ks.keys[name] = privateKey
+	}
+	return ks, nil
+}
+
+// FromEnv - open keystore from EnvKeystorePath with passphrase from EnvKeystorePassphrase,
+// nil is returned if EnvKeystorePath isn't set
+func FromEnv() (*Keystore, error) {
+	path := os.Getenv(EnvKeystorePath)
+	if path == "" {
+		return nil, nil
+	}
+	return Open(path, os.Getenv(EnvKeystorePassphrase))
+}
+
+// Path - file of keystore
+func (ks *Keystore) Path() string {
+	return ks.path
+}
+
+// Save - encrypt keys with new salt and nonce and replace file of keystore,
+// parent directories are created if necessary
+func (ks *Keystore) Save() error {
+	ks.mu.RLock()
+	encoded := make(map[string]string, len(ks.keys))
+	for name, privateKey := range ks.keys {
+		encoded[name] = utils.ConvertPrivateKeyToBase58Check(privateKey)
+	}
+	ks.mu.RUnlock()
+
+	plaintext, err := json.Marshal(encoded)
+	if err != nil {
+		return fmt.Errorf("json marshal keys: %w", err)
+	}
+
+	f := &file{
+		Version: version,
+		KDF:     kdfConfig{Name: kdf, Salt: make([]byte, saltLen), N: scryptN, R: scryptR, P: scryptP},
+		Nonce:   make([]byte, chacha20poly1305.NonceSizeX),
+	}
+	if _, err = rand.Read(f.KDF.Salt); err != nil {
+		return fmt.Errorf("random salt: %w", err)
+	}
+	if _, err = rand.Read(f.Nonce); err != nil {
+		return fmt.Errorf("random nonce: %w", err)
+	}
+	aead, err := newAEAD(ks.passphrase, f.KDF)
+	if err != nil {
+		return err
+	}
+	ad, err := f.additionalData()
+	if err != nil {
+		return err
+	}
+	f.Ciphertext = aead.Seal(nil, f.Nonce, plaintext, ad)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json marshal: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(ks.path), dirMode); err != nil {
+		return fmt.Errorf("create keystore dir: %w", err)
+	}
+	tmp := ks.path + tmpExtension
+	if err = os.WriteFile(tmp, data, fileMode); err != nil {
+		return fmt.Errorf("write keystore: %w", err)
+	}
+	if err = os.Rename(tmp, ks.path); err != nil {
+		return fmt.Errorf("replace keystore: %w", err)
+	}
+	return nil
+}
+
+// Put - add or replace key with the name
+func (ks *Keystore) Put(name string, privateKey ed25519.PrivateKey) error {
+	if name == "" {
+		return errors.New("empty name of key")
+	}
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return fmt.Errorf("incorrect private key length %d", len(privateKey))
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[name] = append(ed25519.PrivateKey(nil), privateKey...)
+	return nil
+}
+
+// Generate - add new generated key with the name
+func (ks *Keystore) Generate(name string) (ed25519.PrivateKey, error) {
+	privateKey, _, err := utils.GeneratePrivateAndPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+	if err = ks.Put(name, privateKey); err != nil {
+		return nil, err
+	}
+	return privateKey, nil
+}
+
+// Get - private key with the name, ErrKeyNotFound is returned if it is absent
+func (ks *Keystore) Get(name string) (ed25519.PrivateKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	privateKey, ok := ks.keys[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+	return append(ed25519.PrivateKey(nil), privateKey...), nil
+}
+
+// Delete - remove key with the name, ErrKeyNotFound is returned if it is absent
+func (ks *Keystore) Delete(name string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, ok := ks.keys[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+	delete(ks.keys, name)
+	return nil
+}
+
+// List - public keys and addresses of keys sorted by name
+func (ks *Keystore) List() ([]Entry, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(ks.keys))
+	for name, privateKey := range ks.keys {
+		publicKey, ok := privateKey.Public().(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("type assertion failed")
+		}
+		address, err := utils.GetAddressByPublicKey(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("get address of %s: %w", name, err)
+		}
+		entries = append(entries, Entry{
+			Name:            name,
+			PublicKeyBase58: utils.ConvertPublicKeyToBase58(publicKey),
+			Address:         address,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// Import - add key in base58 check format of utils.EnvFiatIssuerPrivateKey with the name
+func (ks *Keystore) Import(name, secretKey string) error {
+	privateKey, _, err := utils.GetPrivateKeyFromBase58Check(secretKey)
+	if err != nil {
+		return fmt.Errorf("import %s: %w", name, err)
+	}
+	return ks.Put(name, privateKey)
+}
+
+// Export - key with the name in base58 check format of utils.EnvFiatIssuerPrivateKey
+func (ks *Keystore) Export(name string) (string, error) {
+	privateKey, err := ks.Get(name)
+	if err != nil {
+		return "", err
+	}
+	return utils.ConvertPrivateKeyToBase58Check(privateKey), nil
+}
+
+// Signers - signers of keys with the names, e.g. validators for utils.SignChangePublicKey
+func (ks *Keystore) Signers(names ...string) ([]utils.Signer, error) {
+	signers := make([]utils.Signer, 0, len(names))
+	for _, name := range names {
+		privateKey, err := ks.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := utils.NewKeySigner(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("signer %s: %w", name, err)
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// additionalData - header of file authenticated by AEAD, so kdf parameters can't be changed
+func (f *file) additionalData() ([]byte, error) {
+	ad, err := json.Marshal(struct {
+		Version int       `json:"version"`
+		KDF     kdfConfig `json:"kdf"`
+	}{f.Version, f.KDF})
+	if err != nil {
+		return nil, fmt.Errorf("json marshal header: %w", err)
+	}
+	return ad, nil
+}
+
+// newAEAD - XChaCha20-Poly1305 with key derived from passphrase by scrypt
+// validate - parameters must be the ones written by Save, otherwise file could make scrypt take unbounded memory and time
+func (c kdfConfig) validate() error {
+	if c.N != scryptN || c.R != scryptR || c.P != scryptP {
+		return fmt.Errorf("%w: n=%d r=%d p=%d", ErrUnsupportedKDF, c.N, c.R, c.P)
+	}
+	if len(c.Salt) != saltLen {
+		return fmt.Errorf("%w: salt length %d", ErrUnsupportedKDF, len(c.Salt))
+	}
+	return nil
+}
+
+func newAEAD(passphrase []byte, config kdfConfig) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, config.Salt, config.N, config.R, config.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("scrypt: %w", err)
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/keystore"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// TestKeystore - keys of issuer and fee collector are kept in encrypted keystore file,
+// reopened keystore registers the same identities and issuer is read from keystore without secret in env
+func TestKeystore(t *testing.T) {
+	runner.Run(t, "encrypted keystore of long-lived identities", func(t provider.T) {
+		t.Severity(allure.NORMAL)
+		t.Description("keystore keeps named keys encrypted by passphrase, lists their addresses and exports base58 check format")
+		t.Tags("positive", "negative", "keystore")
+
+		const passphrase = "integration test passphrase"
+
+		var (
+			ctx       = context.Background()
+			issuerKey = os.Getenv(utils.EnvFiatIssuerPrivateKey)
+
+			path      string
+			issuer    *fixtures.Identity
+			collector *fixtures.Identity
+		)
+
+		dir, err := os.MkdirTemp("", "keystore")
+		t.Require().NoError(err)
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		path = filepath.Join(dir, "keys", "keystore.json")
+
+		t.WithNewStep("Import issuer key, generate fee collector key and save keystore", func(sCtx provider.StepCtx) {
+			ks := keystore.New(path, passphrase)
+			sCtx.Require().NoError(ks.Import(keystore.IssuerKey, issuerKey))
+			_, err := ks.Generate("feeCollector")
+			sCtx.Require().NoError(err)
+			sCtx.Require().NoError(ks.Save())
+
+			data, err := os.ReadFile(path)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().False(bytes.Contains(data, []byte(issuerKey)))
+		})
+
+		t.WithNewStep("Keystore can't be opened with wrong passphrase", func(sCtx provider.StepCtx) {
+			_, err := keystore.Open(path, "wrong passphrase")
+			sCtx.Assert().True(errors.Is(err, keystore.ErrDecrypt))
+		})
+
+		t.WithNewStep("Keystore with changed kdf parameters is rejected before key derivation", func(sCtx provider.StepCtx) {
+			data, err := os.ReadFile(path)
+			sCtx.Require().NoError(err)
+
+			for name, change := range map[string]func(kdf map[string]interface{}){
+				"huge n":     func(kdf map[string]interface{}) { kdf["n"] = 1 << 40 },
+				"huge r":     func(kdf map[string]interface{}) { kdf["r"] = 1 << 20 },
+				"short salt": func(kdf map[string]interface{}) { kdf["salt"] = []byte{1} },
+			} {
+				f := map[string]interface{}{}
+				sCtx.Require().NoError(json.Unmarshal(data, &f))
+				kdf, ok := f["kdf"].(map[string]interface{})
+				sCtx.Require().True(ok)
+				change(kdf)
+
+				tampered, err := json.Marshal(f)
+				sCtx.Require().NoError(err)
+				tamperedPath := filepath.Join(dir, "tampered.json")
+				sCtx.Require().NoError(os.WriteFile(tamperedPath, tampered, 0o600))
+
+				_, err = keystore.Open(tamperedPath, passphrase)
+				sCtx.Assert().True(errors.Is(err, keystore.ErrUnsupportedKDF), "%s: %v", name, err)
+			}
+		})
+
+		t.WithNewStep("Reopened keystore lists addresses and registers identities", func(sCtx provider.StepCtx) {
+			ks, err := keystore.Open(path, passphrase)
+			sCtx.Require().NoError(err)
+
+			exported, err := ks.Export(keystore.IssuerKey)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Equal(issuerKey, exported)
+			_, err = ks.Export("validator")
+			sCtx.Assert().True(errors.Is(err, keystore.ErrKeyNotFound))
+
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			collector, err = fixtures.NewUser(ctx, client, fixtures.FromKeystore(ks, "feeCollector"))
+			sCtx.Require().NoError(err)
+
+			entries, err := ks.List()
+			sCtx.Require().NoError(err)
+			sCtx.Require().Len(entries, 2)
+			sCtx.Assert().Equal("feeCollector", entries[0].Name)
+			sCtx.Assert().Equal(collector.Address, entries[0].Address)
+			sCtx.Assert().Equal(keystore.IssuerKey, entries[1].Name)
+			sCtx.Assert().Equal(issuer.Address, entries[1].Address)
+			sCtx.Assert().Equal(issuer.PublicKeyBase58, entries[1].PublicKeyBase58)
+		})
+
+		t.WithNewStep("Issuer is read from keystore if its key isn't set in env", func(sCtx provider.StepCtx) {
+			for env, value := range map[string]string{
+				utils.EnvFiatIssuerPrivateKey:  "",
+				keystore.EnvKeystorePath:       path,
+				keystore.EnvKeystorePassphrase: passphrase,
+			} {
+				env := env
+				previous, ok := os.LookupEnv(env)
+				sCtx.Require().NoError(os.Setenv(env, value))
+				t.Cleanup(func() {
+					if ok {
+						_ = os.Setenv(env, previous)
+					} else {
+						_ = os.Unsetenv(env)
+					}
+				})
+			}
+
+			fromKeystore, err := fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Equal(issuer.Address, fromKeystore.Address)
+		})
+	})
+}
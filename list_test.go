@@ -0,0 +1,202 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/acl"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/swap"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// setListed - add address to list or remove it from list and wait for acl,
+// addresses which are still listed at the end of test are removed in cleanup
+func setListed(ctx context.Context, t provider.T, sCtx provider.StepCtx, address string, list acl.ListType, listed bool) {
+	aclClient := acl.NewClient(client)
+	if listed {
+		t.Cleanup(func() {
+			resp, err := aclClient.CheckAddress(ctx, address)
+			if err != nil {
+				t.Errorf("check %s in %s list: %v", address, list, err)
+				return
+			}
+			if !acl.Listed(resp.Account, list) {
+				return
+			}
+			if _, err = aclClient.DelFromList(ctx, address, list); err != nil {
+				t.Errorf("remove %s from %s list: %v", address, list, err)
+				return
+			}
+			if err = aclClient.WaitListed(ctx, address, list, false); err != nil {
+				t.Errorf("remove %s from %s list: %v", address, list, err)
+			}
+		})
+		_, err := aclClient.AddToList(ctx, address, list)
+		sCtx.Require().NoError(err)
+	} else {
+		_, err := aclClient.DelFromList(ctx, address, list)
+		sCtx.Require().NoError(err)
+	}
+	sCtx.Require().NoError(aclClient.WaitListed(ctx, address, list, listed))
+}
+
+// invokeRejected - signed invoke fails with target error either at invoke or in batch
+func invokeRejected(ctx context.Context, sCtx provider.StepCtx, signer *fixtures.Identity, channel, fcn string, target error, args ...string) {
+	signedArgs, err := signer.Sign(channel, channel, fcn, args...)
+	sCtx.Require().NoError(err)
+	resp, err := client.Invoke(ctx, channel, fcn, signedArgs...)
+	if err != nil {
+		sCtx.Assert().True(errors.Is(err, target), "%s: %v", fcn, err)
+		return
+	}
+	utils.RequireTxFailedWith(ctx, sCtx, client, channel, resp.TransactionID, http.StatusInternalServerError, target)
+}
+
+// invokeSucceeded - signed invoke succeeds in batch
+func invokeSucceeded(ctx context.Context, sCtx provider.StepCtx, signer *fixtures.Identity, channel, fcn string, args ...string) {
+	signedArgs, err := signer.Sign(channel, channel, fcn, args...)
+	sCtx.Require().NoError(err)
+	resp, err := client.Invoke(ctx, channel, fcn, signedArgs...)
+	sCtx.Require().NoError(err)
+	utils.RequireTxSucceeded(ctx, sCtx, client, channel, resp.TransactionID)
+}
+
+// TestBlackList - blacklisted address can't send token by transfer and swapBegin and can't receive it, delisting restores it
+func TestBlackList(t *testing.T) {
+	runner.Run(t, "blacklisted address is blocked in `fiat` chaincode", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("transfer and swapBegin of blacklisted sender and transfer to blacklisted recipient fail, balances don't change")
+		t.Tags("positive", "negative", "acl", "list")
+		requireBatchEvents(t)
+
+		var (
+			ctx       = context.Background()
+			aclClient = acl.NewClient(client)
+
+			issuer, sender, recipient *fixtures.Identity
+		)
+
+		// checkBalances - wait for balances of sender and recipient in fiat
+		checkBalances := func(sCtx provider.StepCtx, senderBalance, recipientBalance int64) {
+			requireBalance(ctx, sCtx, "fiat", sender.Address, amount.New(senderBalance))
+			requireBalance(ctx, sCtx, "fiat", recipient.Address, amount.New(recipientBalance))
+		}
+
+		t.WithNewStep("Register users and emit FIAT token to sender", func(sCtx provider.StepCtx) {
+			var err error
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			sender, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			recipient, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+
+			invokeSucceeded(ctx, sCtx, issuer, "fiat", "emit", sender.Address, "100")
+			checkBalances(sCtx, 100, 0)
+		})
+
+		t.WithNewStep("Add sender to black list", func(sCtx provider.StepCtx) {
+			setListed(ctx, t, sCtx, sender.Address, acl.BlackList, true)
+
+			resp, err := aclClient.CheckAddress(ctx, sender.Address)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(resp.Account.BlackListed)
+			sCtx.Assert().False(resp.Account.GrayListed)
+			sCtx.Assert().Equal(sender.Address, acl.EncodeAddress(resp.Address.Address))
+		})
+
+		t.WithNewStep("Blacklisted sender can't transfer", func(sCtx provider.StepCtx) {
+			invokeRejected(ctx, sCtx, sender, "fiat", "transfer", utils.ErrBlacklisted, recipient.Address, "10", "")
+			checkBalances(sCtx, 100, 0)
+		})
+
+		t.WithNewStep("Blacklisted sender can't begin swap", func(sCtx provider.StepCtx) {
+			key, err := swap.NewKey()
+			sCtx.Require().NoError(err)
+			invokeRejected(ctx, sCtx, sender, "fiat", swap.BeginFn, utils.ErrBlacklisted, FiatName, "CC", "10", swap.Hash(key))
+			checkBalances(sCtx, 100, 0)
+		})
+
+		t.WithNewStep("Delisted sender transfers again", func(sCtx provider.StepCtx) {
+			setListed(ctx, t, sCtx, sender.Address, acl.BlackList, false)
+			invokeSucceeded(ctx, sCtx, sender, "fiat", "transfer", recipient.Address, "10", "")
+			checkBalances(sCtx, 90, 10)
+		})
+
+		t.WithNewStep("Transfer to blacklisted recipient fails until recipient is delisted", func(sCtx provider.StepCtx) {
+			setListed(ctx, t, sCtx, recipient.Address, acl.BlackList, true)
+			invokeRejected(ctx, sCtx, sender, "fiat", "transfer", utils.ErrBlacklisted, recipient.Address, "10", "")
+			checkBalances(sCtx, 90, 10)
+
+			setListed(ctx, t, sCtx, recipient.Address, acl.BlackList, false)
+			invokeSucceeded(ctx, sCtx, sender, "fiat", "transfer", recipient.Address, "10", "")
+			checkBalances(sCtx, 80, 20)
+		})
+	})
+}
+
+// TestGrayList - graylisted address can't sign transactions but still receives token, delisting restores it
+func TestGrayList(t *testing.T) {
+	runner.Run(t, "graylisted address can't sign transactions", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("Signed transactions of graylisted user are rejected, token can be emitted to it, delisting restores rights")
+		t.Tags("positive", "negative", "acl", "list")
+		requireBatchEvents(t)
+
+		var (
+			ctx       = context.Background()
+			aclClient = acl.NewClient(client)
+
+			issuer, user, recipient *fixtures.Identity
+		)
+
+		t.WithNewStep("Register users and emit FIAT token to user", func(sCtx provider.StepCtx) {
+			var err error
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			user, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			recipient, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+
+			invokeSucceeded(ctx, sCtx, issuer, "fiat", "emit", user.Address, "100")
+			requireBalance(ctx, sCtx, "fiat", user.Address, amount.New(100))
+		})
+
+		t.WithNewStep("Add user to gray list", func(sCtx provider.StepCtx) {
+			setListed(ctx, t, sCtx, user.Address, acl.GrayList, true)
+
+			resp, err := aclClient.CheckAddress(ctx, user.Address)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(resp.Account.GrayListed)
+			sCtx.Assert().False(resp.Account.BlackListed)
+		})
+
+		t.WithNewStep("Graylisted user can't transfer and begin swap", func(sCtx provider.StepCtx) {
+			invokeRejected(ctx, sCtx, user, "fiat", "transfer", utils.ErrGraylisted, recipient.Address, "10", "")
+
+			key, err := swap.NewKey()
+			sCtx.Require().NoError(err)
+			invokeRejected(ctx, sCtx, user, "fiat", swap.BeginFn, utils.ErrGraylisted, FiatName, "CC", "10", swap.Hash(key))
+			requireBalance(ctx, sCtx, "fiat", user.Address, amount.New(100))
+		})
+
+		t.WithNewStep("Graylisted user receives emitted token", func(sCtx provider.StepCtx) {
+			invokeSucceeded(ctx, sCtx, issuer, "fiat", "emit", user.Address, "5")
+			requireBalance(ctx, sCtx, "fiat", user.Address, amount.New(105))
+		})
+
+		t.WithNewStep("Delisted user transfers again", func(sCtx provider.StepCtx) {
+			setListed(ctx, t, sCtx, user.Address, acl.GrayList, false)
+			invokeSucceeded(ctx, sCtx, user, "fiat", "transfer", recipient.Address, "10", "")
+			requireBalance(ctx, sCtx, "fiat", recipient.Address, amount.New(10))
+		})
+	})
+}
@@ -0,0 +1,152 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/cassette"
+	"github.com/tickets-dao/integration/fakeproxy"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/utils"
+	"golang.org/x/crypto/ed25519"
+)
+
+var (
+	// client - client of hlf proxy service shared by all tests
+	client *utils.Client
+	// fake - fake hlf proxy service started by TestMain, nil if tests are executed against real environment
+	fake *fakeproxy.Server
+	// keys - deriver of deterministic test identities with seed from utils.EnvKeySeed
+	keys *utils.KeyDeriver
+)
+
+// TestMain - run suite against fake hlf proxy service if environment prepared by 'run' is absent.
+// Traffic is recorded into file from cassette.EnvCassettePath if it is set
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	if os.Getenv(utils.EnvHlfProxyURL) == "" {
+		var err error
+		if fake, err = startFakeProxy(); err != nil {
+			fmt.Printf("start fake hlf proxy: %v\n", err)
+			return 1
+		}
+		defer fake.Close()
+	}
+
+	var opts []utils.ClientOption
+	if fake != nil {
+		opts = append(opts, utils.WithBatchEvents())
+	}
+	if path := os.Getenv(cassette.EnvCassettePath); path != "" {
+		recorder := cassette.NewRecorder(nil)
+		opts = append(opts, utils.WithHTTPClient(&http.Client{Transport: recorder}))
+		defer func() {
+			if err := recorder.Save(path); err != nil {
+				fmt.Printf("save cassette: %v\n", err)
+			}
+		}()
+	}
+
+	var err error
+	if keys, err = utils.KeyDeriverFromEnv(); err != nil {
+		fmt.Printf("create key deriver: %v\n", err)
+		return 1
+	}
+	fmt.Printf("%s=%s\n", utils.EnvKeySeed, keys.Seed())
+
+	if client, err = utils.NewClientFromEnv(opts...); err != nil {
+		fmt.Printf("create hlf proxy client: %v\n", err)
+		return 1
+	}
+
+	return m.Run()
+}
+
+// attachKeySeed - attach seed of derived keys to report once per test, so failed run can be repeated
+// with the same identities
+func attachKeySeed(t provider.T) {
+	t.WithNewAttachment(utils.EnvKeySeed, allure.Text, []byte(keys.Seed()))
+}
+
+// derivedKey - option of fixtures.NewUser with key derived from path, test must call attachKeySeed
+func derivedKey(path string) fixtures.Option {
+	return fixtures.WithDerivedKey(keys, path)
+}
+
+// requireBatchEvents - skip test checking transactions by batch events if hlf proxy service doesn't provide them,
+// otherwise utils.RequireTxSucceeded and utils.RequireTxFailedWith fail the test
+func requireBatchEvents(t provider.T) {
+	if !client.BatchEvents() {
+		t.Skip("batch events are unavailable in hlf proxy service: failures of transactions in batch can't be checked")
+	}
+}
+
+// requireBalance - wait until balance of address in channel is equal to expected.
+// Transactions are checked by state because batch events are available only in fake hlf proxy service
+func requireBalance(ctx context.Context, sCtx provider.StepCtx, channel, address string, expected *big.Int) {
+	_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+		return client.Query(ctx, channel, utils.BalanceOfFn, address)
+	}, utils.AmountEquals(expected))
+	sCtx.Require().NoError(err, "balance of %s in %s: expected %s", address, channel, amount.String(expected))
+}
+
+// requireAllowedBalance - wait until allowed balance of token of address in channel is equal to expected, see requireBalance
+func requireAllowedBalance(ctx context.Context, sCtx provider.StepCtx, channel, address, token string, expected *big.Int) {
+	_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+		return client.Query(ctx, channel, utils.AllowedBalanceOfFn, address, token)
+	}, utils.AmountEquals(expected))
+	sCtx.Require().NoError(err, "allowed balance of %s of %s in %s: expected %s", token, address, channel, amount.String(expected))
+}
+
+// startFakeProxy - start fake hlf proxy service and set environment variables expected by tests
+func startFakeProxy() (*fakeproxy.Server, error) {
+	issuerPrivateKey, issuerPublicKey, err := utils.GeneratePrivateAndPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate issuer key: %w", err)
+	}
+
+	const validatorsCount = 2
+	validatorPublicKeys := make([]ed25519.PublicKey, validatorsCount)
+	validatorPrivateKeys := make([]string, validatorsCount)
+	for i := range validatorPublicKeys {
+		privateKey, publicKey, err := utils.GeneratePrivateAndPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("generate validator key: %w", err)
+		}
+		validatorPublicKeys[i] = publicKey
+		validatorPrivateKeys[i] = utils.ConvertPrivateKeyToBase58Check(privateKey)
+	}
+
+	authToken := base58.Encode(issuerPublicKey)
+	proxy := fakeproxy.New(
+		fakeproxy.WithAuthToken(authToken),
+		fakeproxy.WithIssuer(issuerPublicKey),
+		fakeproxy.WithValidators(validatorPublicKeys...),
+	)
+
+	for env, value := range map[string]string{
+		utils.EnvHlfProxyURL:          proxy.URL(),
+		utils.EnvHlfProxyAuthToken:    authToken,
+		utils.EnvFiatIssuerPrivateKey: utils.ConvertPrivateKeyToBase58Check(issuerPrivateKey),
+		utils.EnvValidatorPrivateKeys: strings.Join(validatorPrivateKeys, ","),
+	} {
+		if err = os.Setenv(env, value); err != nil {
+			proxy.Close()
+			return nil, fmt.Errorf("set %s: %w", env, err)
+		}
+	}
+
+	return proxy, nil
+}
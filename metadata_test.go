@@ -2,14 +2,12 @@ package integration
 
 import (
 	"context"
-	"os"
 	"strings"
 	"testing"
 
 	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/ozontech/allure-go/pkg/framework/runner"
-	"github.com/tickets-dao/integration/utils"
 )
 
 func TestMetadata(t *testing.T) {
@@ -22,8 +20,7 @@ func TestMetadata(t *testing.T) {
 		t.Tags("smoke", "positive", "metadata")
 		for _, cc := range ccs {
 			t.WithNewAsyncStep("Get metadata from chaincode `"+cc+"`", func(sCtx provider.StepCtx) {
-				_, err := utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), cc, "metadata")
+				_, err := client.Query(ctx, cc, "metadata")
 				sCtx.Assert().NoError(err)
 			})
 		}
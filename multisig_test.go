@@ -0,0 +1,124 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// TestMultisigTransfer - create multisig 2 of 3, emit amount to multisig and transfer it to user with signatures of members
+func TestMultisigTransfer(t *testing.T) {
+	runner.Run(t, "Transfer of `fiat` token from multisig wallet", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("Testing transfer from multisig wallet signed by N of M members")
+		t.Tags("positive", "negative", "multisig")
+
+		var (
+			ctx = context.Background()
+
+			issuer, user, member1, member2, member3 *fixtures.Identity
+			multisig                                *fixtures.Multisig
+		)
+
+		t.WithNewStep("Register users in `acl` chaincode", func(sCtx provider.StepCtx) {
+			register := func(title string, identity **fixtures.Identity, register func() (*fixtures.Identity, error)) {
+				sCtx.WithNewAsyncStep(title, func(sCtx provider.StepCtx) {
+					var err error
+					*identity, err = register()
+					sCtx.Require().NoError(err)
+				})
+			}
+			newUser := func() (*fixtures.Identity, error) { return fixtures.NewUser(ctx, client) }
+
+			register("Register issuer with crypto from env", &issuer, func() (*fixtures.Identity, error) {
+				return fixtures.NewIssuer(ctx, client)
+			})
+			register("Register user", &user, newUser)
+			register("Register first member of multisig", &member1, newUser)
+			register("Register second member of multisig", &member2, newUser)
+			register("Register third member of multisig", &member3, newUser)
+		})
+
+		t.WithNewStep("Register multisig 2 of 3 in `acl` chaincode", func(sCtx provider.StepCtx) {
+			var err error
+			multisig, err = fixtures.NewMultisig(ctx, client, 2, member1, member2, member3)
+			sCtx.Require().NoError(err)
+
+			resp, err := client.Query(ctx, "acl", "checkKeys", utils.MultisigKey(multisig.Policy))
+			sCtx.Require().NoError(err)
+
+			aclResponse := &pb.AclResponse{}
+			sCtx.Require().NoError(proto.Unmarshal(resp.Payload, aclResponse))
+			sCtx.Assert().True(aclResponse.GetAddress().GetAddress().GetIsMultisig())
+			sCtx.Assert().Equal(uint32(2), aclResponse.GetAddress().GetSignaturePolicy().GetN())
+		})
+
+		t.WithNewStep("Emit FIAT token to multisig", func(sCtx provider.StepCtx) {
+			signedEmitArgs, err := issuer.Sign("fiat", "fiat", "emit", multisig.Address, "2")
+			sCtx.Require().NoError(err)
+
+			_, err = client.Invoke(ctx, "fiat", "emit", signedEmitArgs...)
+			sCtx.Require().NoError(err)
+
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, "fiat", "balanceOf", multisig.Address)
+			}, utils.AmountEquals(amount.New(2)))
+			sCtx.Assert().NoError(err)
+		})
+
+		t.WithNewStep("Transfer FIAT token from multisig to user", func(sCtx provider.StepCtx) {
+			sCtx.WithNewStep("Sign transfer by one member, policy requires two", func(sCtx provider.StepCtx) {
+				signedTransferArgs, err := multisig.Sign([]*fixtures.Identity{member1}, "fiat", "fiat", "transfer", user.Address, "1", "ref multisig")
+				sCtx.Assert().Error(err)
+				sCtx.Assert().Nil(signedTransferArgs)
+			})
+
+			sCtx.WithNewStep("Invoke fiat chaincode to transfer signed by two members", func(sCtx provider.StepCtx) {
+				signedTransferArgs, err := multisig.Sign([]*fixtures.Identity{member1, member3}, "fiat", "fiat", "transfer", user.Address, "1", "ref multisig")
+				sCtx.Require().NoError(err)
+
+				_, err = client.Invoke(ctx, "fiat", "transfer", signedTransferArgs...)
+				sCtx.Assert().NoError(err)
+			})
+
+			sCtx.WithNewStep("Check balances of multisig and user", func(sCtx provider.StepCtx) {
+				_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+					return client.Query(ctx, "fiat", "balanceOf", user.Address)
+				}, utils.AmountEquals(amount.New(1)))
+				sCtx.Assert().NoError(err)
+
+				_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+					return client.Query(ctx, "fiat", "balanceOf", multisig.Address)
+				}, utils.AmountEquals(amount.New(1)))
+				sCtx.Assert().NoError(err)
+			})
+		})
+
+		t.WithNewStep("Invoke fiat chaincode to transfer with signature removed after signing", func(sCtx provider.StepCtx) {
+			signers, err := fixtures.Signers(member1, member2)
+			sCtx.Require().NoError(err)
+			tx, err := utils.SignMultisigTx(multisig.Policy, signers,
+				"fiat", "fiat", "transfer", utils.DefaultNonceSource.Next(member1.PublicKey), user.Address, "1", "ref multisig")
+			sCtx.Require().NoError(err)
+
+			for i := range tx.Signatures {
+				if bytes.Equal(tx.Policy.PubKeys[i], member2.PublicKey) {
+					tx.Signatures[i] = nil
+				}
+			}
+			sCtx.Assert().Error(tx.Verify())
+
+			_, err = client.Invoke(ctx, "fiat", "transfer", tx.Args()...)
+			sCtx.Assert().Error(err)
+		})
+	})
+}
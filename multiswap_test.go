@@ -1,161 +1,224 @@
 package integration
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"testing"
-	"time"
 
-	"github.com/btcsuite/btcutil/base58"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/ozontech/allure-go/pkg/framework/runner"
 	"github.com/stretchr/testify/assert"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/industrial"
+	"github.com/tickets-dao/integration/swap"
 	"github.com/tickets-dao/integration/utils"
 )
 
 const FiatName = "FIAT"
 
+// skipMultiSwap - multi swap is broken in foundation, so multi swap tests are executed only against fake hlf proxy service
+func skipMultiSwap(t provider.T) {
+	if fake == nil {
+		t.Skip("reason: https://github.com/tickets-dao/foundation/-/issues/48")
+	}
+}
+
+// waitMultiSwapRemoved - wait until multi swap is removed from channel after multiSwapDone
+func waitMultiSwapRemoved(ctx context.Context, channel, id string) error {
+	_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+		_, err := swap.MultiSwapGet(ctx, client, channel, id)
+		if errors.Is(err, utils.ErrSwapNotFound) {
+			return &utils.Response{}, nil
+		}
+		return nil, fmt.Errorf("multi swap %s isn't removed from %s: %v", id, channel, err)
+	}, nil)
+	return err
+}
+
 // TestMultiSwap - create user, emit amount to fiat, multi swap amount from fiat channel to cc channel, check amount is moved
 func TestMultiSwap(t *testing.T) { // relates issue github.com/tickets-dao/foundation/-/issues/48"
-	// skipped due to refactoring
-	t.Skip()
 	runner.Run(t, "multiswap token from fiat to cc and multiswap back", func(t provider.T) {
-		t.Skip("reason: https://github.com/tickets-dao/foundation/-/issues/48")
+		skipMultiSwap(t)
 		t.Tags("positive", "multiswap")
+		ctx := context.Background()
 
-		t.NewStep("Prepare service to connect with API hlf proxy service")
-		hlfProxy := utils.NewHlfProxyService(os.Getenv(utils.EnvHlfProxyURL), os.Getenv(utils.EnvHlfProxyAuthToken))
-
-		t.NewStep("addUser. Get 'private key' and 'public key' Issuer user from 'ed25519 private key in format base 58 check'")
-		issuerFiatEd25519PrivateKey, issuerFiatEd25519PublicKey, err := utils.GetPrivateKeyFromBase58Check(os.Getenv(utils.EnvFiatIssuerPrivateKey))
-		assert.NoError(t, err)
-		issuerEd25519PublicKeyBase58 := base58.Encode(issuerFiatEd25519PublicKey)
-		t.NewStep("addUser. Try to add issuer user in acl, no handle err because issuer may already exist")
-		_, err = hlfProxy.Invoke("acl", "addUser", issuerEd25519PublicKeyBase58, "test", "testuser", "true")
-		assert.NoError(t, err)
-
-		t.NewStep("after add user. check issuer public key")
-		_, err = hlfProxy.Query("acl", "checkKeys", issuerEd25519PublicKeyBase58)
-		t.Assert().NoError(err)
+		t.NewStep("Register issuer with crypto from env in acl, issuer may already exist")
+		issuer, err := fixtures.NewIssuer(ctx, client)
+		t.Require().NoError(err)
 
-		t.NewStep("addUser. Generate private key for user")
-		userEd25519PrivateKey, userEd25519PublicKey, err := utils.GeneratePrivateAndPublicKey()
-		assert.NoError(t, err)
-
-		userPublicKeyBase58 := base58.Encode(userEd25519PublicKey)
-		userAddressBase58Check, err := utils.GetAddressByPublicKey(userEd25519PublicKey)
-		assert.NoError(t, err)
-
-		t.NewStep("addUser. invoke chaincode acl with method addUser, and create user")
-		_, err = hlfProxy.Invoke("acl", "addUser", userPublicKeyBase58, "test", "testuser", "true")
-		assert.NoError(t, err)
-
-		t.NewStep("after add user. check user public key")
-		_, err = hlfProxy.Query("acl", "checkKeys", userPublicKeyBase58)
-		t.Assert().NoError(err)
+		t.NewStep("Register user in acl")
+		user, err := fixtures.NewUser(ctx, client)
+		t.Require().NoError(err)
 
 		t.NewStep("Emit 1 FIAT token to user")
 		emitAmount := "1"
-		emitArgs := []string{userAddressBase58Check, emitAmount}
-		signedEmitArgs, err := utils.Sign(issuerFiatEd25519PrivateKey, issuerFiatEd25519PublicKey, "fiat", "fiat", "emit", emitArgs)
+		signedEmitArgs, err := issuer.Sign("fiat", "fiat", "emit", user.Address, emitAmount)
 		assert.NoError(t, err)
-		_, err = hlfProxy.Invoke("fiat", "emit", signedEmitArgs...)
+		_, err = client.Invoke(ctx, "fiat", "emit", signedEmitArgs...)
 		assert.NoError(t, err)
-		time.Sleep(utils.BatchTransactionTimeout)
 
 		t.NewStep("After emit need to check balance FIAT token in fiat channel by user address")
-		resp, err := hlfProxy.Query("fiat", "balanceOf", userAddressBase58Check)
+		_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+			return client.Query(ctx, "fiat", "balanceOf", user.Address)
+		}, utils.AmountEquals(amount.New(1)))
 		assert.NoError(t, err)
-		assert.Equal(t, "\"1\"", string(resp.Payload))
 
 		t.NewStep("Start multi swap process with call method multiSwapBegin in fiat channel. We start to move 1 FIAT token from 'fiat' channel to 'cc' channel")
-		tokenPrefix := FiatName
-		multiSwapAmount := "1"
-		assets := fmt.Sprintf("{\"Assets\":[{\"group\":\"%s\",\"amount\":\"%s\"}]}", tokenPrefix, multiSwapAmount)
-		channelTo := "CC"
-		multiSwapBeginArgs := []string{tokenPrefix, assets, channelTo, DefaultSwapHash}
-		signedMultiSwapBeginArgs, err := utils.Sign(userEd25519PrivateKey, userEd25519PublicKey, "fiat", "fiat", "multiSwapBegin", multiSwapBeginArgs)
-		assert.NoError(t, err)
-		multiSwapBeginResp, err := hlfProxy.Invoke("fiat", "multiSwapBegin", signedMultiSwapBeginArgs...)
-		assert.NoError(t, err)
-		time.Sleep(utils.BatchTransactionTimeout)
-
-		t.NewStep("By transaction id from response multiSwapBegin need to check multi swap record in fiat channel")
-		_, err = hlfProxy.Query("fiat", "multiSwapGet", multiSwapBeginResp.TransactionID)
-		assert.NoError(t, err)
-
-		t.NewStep("By transaction id from response multiSwapBegin need to check multi swap record in cc channel")
-		_, err = hlfProxy.Query("cc", "multiSwapGet", multiSwapBeginResp.TransactionID)
-		assert.NoError(t, err)
+		multiSwap := swap.NewMultiSwap(FiatName).Asset(FiatName, amount.New(1)).To("cc")
+		multiSwapID, err := multiSwap.Begin(ctx, client, user, "fiat")
+		t.Require().NoError(err)
+
+		t.NewStep("By transaction id from response multiSwapBegin need to check multi swap record in fiat and cc channels")
+		for _, channel := range []string{"fiat", "cc"} {
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				_, err := swap.MultiSwapGet(ctx, client, channel, multiSwapID)
+				return &utils.Response{}, err
+			}, nil)
+			assert.NoError(t, err)
+		}
+
+		t.NewStep("Multi swap record in cc channel contains asset and owner")
+		ccMultiSwap, err := swap.MultiSwapGet(ctx, client, "cc", multiSwapID)
+		t.Require().NoError(err)
+		t.Require().Len(ccMultiSwap.Assets, 1)
+		assert.Equal(t, FiatName, ccMultiSwap.Assets[0].Group)
+		assert.True(t, amount.Equal(amount.New(1), amount.FromBytes(ccMultiSwap.Assets[0].Amount)))
+		assert.Equal(t, "CC", ccMultiSwap.To)
+		assert.NoError(t, swap.VerifyKey(ccMultiSwap, multiSwap.SwapKey()))
 
 		t.NewStep("After multiSwapBegin need to check balance FIAT token in fiat channel by user address. This balance must change")
-		resp, err = hlfProxy.Query("fiat", "balanceOf", userAddressBase58Check)
+		balance, err := client.BalanceOf(ctx, "fiat", user.Address)
 		assert.NoError(t, err)
-		assert.Equal(t, "\"0\"", string(resp.Payload))
+		assert.True(t, amount.IsZero(balance))
 
 		t.NewStep("After multiSwapBegin need to check allowed balance FIAT token in fiat channel by user address.")
-		resp, err = hlfProxy.Query("cc", "allowedBalanceOf", userAddressBase58Check, "FIAT")
+		balance, err = client.AllowedBalanceOf(ctx, "cc", user.Address, "FIAT")
 		assert.NoError(t, err)
-		assert.Equal(t, "\"0\"", string(resp.Payload))
+		assert.True(t, amount.IsZero(balance))
 
 		t.NewStep("Complete multi swap process. Invoke multiSwapDone")
-		_, err = hlfProxy.Invoke("cc", "multiSwapDone", multiSwapBeginResp.TransactionID, DefaultSwapKey)
+		err = swap.MultiSwapDone(ctx, client, "cc", multiSwapID, multiSwap.SwapKey())
 		assert.NoError(t, err)
-		time.Sleep(utils.BatchTransactionTimeout)
-
-		t.NewStep("After multiSwapDone need to check balance FIAT token in fiat channel by user address.")
-		resp, err = hlfProxy.Query("fiat", "balanceOf", userAddressBase58Check)
-		assert.NoError(t, err)
-		assert.Equal(t, "\"0\"", string(resp.Payload))
+		assert.NoError(t, waitMultiSwapRemoved(ctx, "cc", multiSwapID))
 
 		t.NewStep("After multiSwapDone need to check allowed balance FIAT token in fiat channel by user address. This balance must change")
-		resp, err = hlfProxy.Query("cc", "allowedBalanceOf", userAddressBase58Check, "FIAT")
+		_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+			return client.Query(ctx, "cc", "allowedBalanceOf", user.Address, "FIAT")
+		}, utils.AmountEquals(amount.New(1)))
 		assert.NoError(t, err)
-		assert.Equal(t, "\"1\"", string(resp.Payload))
 
 		t.NewStep("Begin multiswap - back FIAT token from cc to fiat through multi swap")
-		backTokenPrefix := FiatName
-		backAmount := "1"
-		backAssets := fmt.Sprintf("{\"Assets\":[{\"group\":\"%s\",\"amount\":\"%s\"}]}", backTokenPrefix, backAmount)
-		backChannelTo := FiatName
-		backSwapBeginArgs := []string{backTokenPrefix, backAssets, backChannelTo, DefaultSwapHash}
-		backSignedSwapBeginArgs, err := utils.Sign(userEd25519PrivateKey, userEd25519PublicKey, "cc", "cc", "multiSwapBegin", backSwapBeginArgs)
-		assert.NoError(t, err)
-		backMultiSwapBegin, err := hlfProxy.Invoke("cc", "multiSwapBegin", backSignedSwapBeginArgs...)
-		assert.NoError(t, err)
-		time.Sleep(utils.BatchTransactionTimeout)
+		backMultiSwap := swap.NewMultiSwap(FiatName).Asset(FiatName, amount.New(1)).To("fiat")
+		backMultiSwapID, err := backMultiSwap.Begin(ctx, client, user, "cc")
+		t.Require().NoError(err)
 
 		t.NewStep("swapGet txID in fiat channel")
-		_, err = hlfProxy.Query("fiat", "multiSwapGet", backMultiSwapBegin.TransactionID)
+		_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+			_, err := swap.MultiSwapGet(ctx, client, "fiat", backMultiSwapID)
+			return &utils.Response{}, err
+		}, nil)
 		assert.NoError(t, err)
 
-		t.NewStep("swapGet txID in cc channel")
-		_, err = hlfProxy.Query("cc", "multiSwapGet", backMultiSwapBegin.TransactionID)
-		assert.NoError(t, err)
-
-		t.NewStep("After multiSwapBegin need to check balance FIAT token in fiat channel by user address.")
-		resp, err = hlfProxy.Query("fiat", "balanceOf", userAddressBase58Check)
-		assert.NoError(t, err)
-		assert.Equal(t, "\"0\"", string(resp.Payload))
-
-		t.NewStep("After multiSwapBegin need to check allowed balance FIAT token in fiat channel by user address. This balance must change")
-		resp, err = hlfProxy.Query("cc", "allowedBalanceOf", userAddressBase58Check, "FIAT")
-		assert.NoError(t, err)
-		assert.Equal(t, "\"0\"", string(resp.Payload))
-
 		t.NewStep("Complete multi swap process. Invoke multiSwapDone for back FIAT token to 'fiat' channel")
-		_, err = hlfProxy.Invoke("fiat", "multiSwapDone", backMultiSwapBegin.TransactionID, DefaultSwapKey)
+		err = swap.MultiSwapDone(ctx, client, "fiat", backMultiSwapID, backMultiSwap.SwapKey())
 		assert.NoError(t, err)
-		time.Sleep(utils.BatchTransactionTimeout)
 
 		t.NewStep("After multiSwapDone need to check balance FIAT token in fiat channel by user address. This balance must change")
-		resp, err = hlfProxy.Query("fiat", "balanceOf", userAddressBase58Check)
+		_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+			return client.Query(ctx, "fiat", "balanceOf", user.Address)
+		}, utils.AmountEquals(amount.New(1)))
 		assert.NoError(t, err)
-		assert.NotNil(t, resp)
 
-		t.NewStep("After multiSwapDone need to check allowed balance FIAT token in fiat channel by user address")
-		resp, err = hlfProxy.Query("cc", "allowedBalanceOf", userAddressBase58Check, "FIAT")
+		t.NewStep("After multiSwapDone need to check allowed balance FIAT token in cc channel by user address")
+		balance, err = client.AllowedBalanceOf(ctx, "cc", user.Address, "FIAT")
 		assert.NoError(t, err)
-		assert.NotNil(t, resp)
+		assert.True(t, amount.IsZero(balance))
+	})
+}
+
+// TestMultiSwapIndustrialGroups - transfer two groups of industrial token to user and multi swap both groups to cc in one call
+func TestMultiSwapIndustrialGroups(t *testing.T) {
+	runner.Run(t, "multiswap of several industrial groups from industrial to cc", func(t provider.T) {
+		skipMultiSwap(t)
+		t.Tags("positive", "multiswap", "industrial")
+		ctx := context.Background()
+
+		t.NewStep("Register issuer and user in acl")
+		issuer, err := fixtures.NewIssuer(ctx, client)
+		t.Require().NoError(err)
+		user, err := fixtures.NewUser(ctx, client)
+		t.Require().NoError(err)
+
+		t.NewStep("Initialize industrial token")
+		signedInitArgs, err := issuer.Sign(itSymbol, itSymbol, "initialize")
+		t.Require().NoError(err)
+		resp, err := client.Invoke(ctx, itSymbol, "initialize", signedInitArgs...)
+		t.Require().NoError(err)
+		t.Require().NoError(client.WaitForTx(ctx, itSymbol, resp.TransactionID, func(ctx context.Context) (*utils.Response, error) {
+			return client.Query(ctx, itSymbol, utils.IndustrialBalanceOfFn, issuer.Address)
+		}, utils.IndustrialIssued()))
+
+		t.NewStep("Take two groups of industrial token from metadata")
+		token, err := industrial.Read(ctx, client, itSymbol)
+		t.Require().NoError(err)
+		t.Require().GreaterOrEqual(len(token.Groups), 2)
+		first, second := token.Groups[0].Id, token.Groups[1].Id
+		groups := map[string]int64{first: 3, second: 5}
+
+		t.NewStep("Transfer groups of industrial token to user")
+		for group, value := range groups {
+			signedArgs, err := issuer.Sign(itSymbol, itSymbol, "transferIndustrial", user.Address, group, amount.String(amount.New(value)), "")
+			t.Require().NoError(err)
+			_, err = client.Invoke(ctx, itSymbol, "transferIndustrial", signedArgs...)
+			t.Require().NoError(err)
+		}
+		for group, value := range groups {
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, itSymbol, "industrialBalanceOf", user.Address)
+			}, utils.IndustrialAmountEquals(group, amount.New(value)))
+			t.Require().NoError(err)
+		}
+
+		t.NewStep("Builder rejects invalid assets")
+		_, err = swap.NewMultiSwap("INDUSTRIAL").Asset(first, amount.New(0)).To("cc").Args()
+		assert.Error(t, err)
+		_, err = swap.NewMultiSwap("INDUSTRIAL").Asset(first, amount.New(1)).Asset(first, amount.New(1)).To("cc").Args()
+		assert.Error(t, err)
+
+		t.NewStep("Multi swap both groups from industrial to cc")
+		builder := swap.NewMultiSwap("INDUSTRIAL").To("cc")
+		for _, group := range []string{first, second} {
+			builder.Asset(group, amount.New(groups[group]))
+		}
+		multiSwapID, err := builder.Begin(ctx, client, user, itSymbol)
+		t.Require().NoError(err)
+
+		_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+			_, err := swap.MultiSwapGet(ctx, client, "cc", multiSwapID)
+			return &utils.Response{}, err
+		}, nil)
+		t.Require().NoError(err)
+
+		multiSwap, err := swap.MultiSwapGet(ctx, client, "cc", multiSwapID)
+		t.Require().NoError(err)
+		assert.Len(t, multiSwap.Assets, len(groups))
+		assert.NoError(t, swap.VerifyKey(multiSwap, builder.SwapKey()))
+
+		t.NewStep("Complete multi swap in cc")
+		t.Require().NoError(swap.MultiSwapDone(ctx, client, "cc", multiSwapID, builder.SwapKey()))
+		assert.NoError(t, waitMultiSwapRemoved(ctx, "cc", multiSwapID))
+
+		t.NewStep("Check allowed balances of groups in cc and industrial balances of user")
+		for group, value := range groups {
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, "cc", "allowedBalanceOf", user.Address, token.Key(group))
+			}, utils.AmountEquals(amount.New(value)))
+			assert.NoError(t, err)
+		}
+		balances, err := client.IndustrialBalanceOf(ctx, itSymbol, user.Address)
+		assert.NoError(t, err)
+		assert.Empty(t, balances)
 	})
 }
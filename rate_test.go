@@ -0,0 +1,219 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/rates"
+	"github.com/tickets-dao/integration/swap"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// TestBuyTokenAndBuyBack - set rates of FIAT in cc, buy CC token for FIAT swapped to cc inside limits and buy it back
+func TestBuyTokenAndBuyBack(t *testing.T) {
+	runner.Run(t, "buy `cc` token for FIAT by rate and buy it back", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("buyToken and buyBack move token and FIAT by rate, amounts outside of min and max are rejected")
+		t.Tags("positive", "negative", "rate")
+		requireBatchEvents(t)
+
+		var (
+			ctx = context.Background()
+
+			issuer, buyer *fixtures.Identity
+			buyRate       *pb.TokenRate
+			buyBackRate   *pb.TokenRate
+			balances      rates.Balances
+		)
+
+		// balancesOf - CC token and allowed FIAT of buyer in cc
+		balancesOf := func(sCtx provider.StepCtx) rates.Balances {
+			token, err := client.BalanceOf(ctx, "cc", buyer.Address)
+			sCtx.Require().NoError(err)
+			currency, err := client.AllowedBalanceOf(ctx, "cc", buyer.Address, FiatName)
+			sCtx.Require().NoError(err)
+			return rates.Balances{Token: token, Currency: currency}
+		}
+
+		// waitRates - wait until rates of cc satisfy check
+		waitRates := func(sCtx provider.StepCtx, check func(tokenRates []*pb.TokenRate) error) {
+			_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				tokenRates, err := rates.Read(ctx, client, "cc")
+				if err != nil {
+					return nil, err
+				}
+				if err = check(tokenRates); err != nil {
+					return nil, err
+				}
+				return &utils.Response{}, nil
+			}, nil)
+			sCtx.Require().NoError(err)
+		}
+
+		// deal - invoke buyToken or buyBack and compare balances of buyer with calculated ones
+		deal := func(sCtx provider.StepCtx, rate *pb.TokenRate, value int64) {
+			expected, err := rates.Deal(rate, balances, amount.New(value))
+			sCtx.Require().NoError(err)
+
+			invoke := rates.BuyToken
+			if rate.DealType == rates.DealBuyBack {
+				invoke = rates.BuyBack
+			}
+			resp, err := invoke(ctx, client, buyer, "cc", FiatName, amount.New(value))
+			sCtx.Require().NoError(err)
+			utils.RequireTxSucceeded(ctx, sCtx, client, "cc", resp.TransactionID)
+
+			requireBalance(ctx, sCtx, "cc", buyer.Address, expected.Token)
+			requireAllowedBalance(ctx, sCtx, "cc", buyer.Address, FiatName, expected.Currency)
+			balances = balancesOf(sCtx)
+		}
+
+		t.WithNewStep("Register users, emit FIAT token to buyer and swap it to cc", func(sCtx provider.StepCtx) {
+			var err error
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			buyer, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+
+			signedArgs, err := issuer.Sign("fiat", "fiat", "emit", buyer.Address, "1000")
+			sCtx.Require().NoError(err)
+			resp, err := client.Invoke(ctx, "fiat", "emit", signedArgs...)
+			sCtx.Require().NoError(err)
+			utils.RequireTxSucceeded(ctx, sCtx, client, "fiat", resp.TransactionID)
+			requireBalance(ctx, sCtx, "fiat", buyer.Address, amount.New(1000))
+
+			_, err = swap.NewSwapper(client).Transfer(ctx, buyer, FiatName, "fiat", "cc", amount.New(1000))
+			sCtx.Require().NoError(err)
+			requireAllowedBalance(ctx, sCtx, "cc", buyer.Address, FiatName, amount.New(1000))
+			balances = balancesOf(sCtx)
+		})
+
+		t.WithNewStep("Set rate 2 of buyToken with limits 10..100 and rate 1.5 of buyBack without max", func(sCtx provider.StepCtx) {
+			t.Cleanup(func() {
+				for _, dealType := range []string{rates.DealBuyToken, rates.DealBuyBack} {
+					dealType := dealType
+					resp, err := rates.DeleteRate(ctx, client, issuer, "cc", dealType, FiatName)
+					if err == nil {
+						err = client.WaitForTx(ctx, "cc", resp.TransactionID, func(ctx context.Context) (*utils.Response, error) {
+							tokenRates, err := rates.Read(ctx, client, "cc")
+							if err != nil {
+								return nil, err
+							}
+							if _, ok := rates.Find(tokenRates, dealType, FiatName); ok {
+								return nil, fmt.Errorf("rate %s of %s isn't deleted", dealType, FiatName)
+							}
+							return &utils.Response{}, nil
+						}, nil)
+					}
+					if err != nil {
+						t.Errorf("delete rate %s: %v", dealType, err)
+					}
+				}
+			})
+
+			for _, invoke := range []func() (*utils.Response, error){
+				func() (*utils.Response, error) {
+					return rates.SetRate(ctx, client, issuer, "cc", rates.DealBuyToken, FiatName, rates.Rate(2, 1))
+				},
+				func() (*utils.Response, error) {
+					return rates.SetLimits(ctx, client, issuer, "cc", rates.DealBuyToken, FiatName, amount.New(10), amount.New(100))
+				},
+				func() (*utils.Response, error) {
+					return rates.SetRate(ctx, client, issuer, "cc", rates.DealBuyBack, FiatName, rates.Rate(3, 2))
+				},
+				func() (*utils.Response, error) {
+					return rates.SetLimits(ctx, client, issuer, "cc", rates.DealBuyBack, FiatName, amount.New(1), amount.New(0))
+				},
+			} {
+				resp, err := invoke()
+				sCtx.Require().NoError(err)
+				utils.RequireTxSucceeded(ctx, sCtx, client, "cc", resp.TransactionID)
+			}
+
+			waitRates(sCtx, func(tokenRates []*pb.TokenRate) error {
+				buy, ok := rates.Find(tokenRates, rates.DealBuyToken, FiatName)
+				if !ok || !amount.Equal(amount.New(10), amount.FromBytes(buy.Min)) {
+					return fmt.Errorf("rate %s of %s isn't set", rates.DealBuyToken, FiatName)
+				}
+				buyBack, ok := rates.Find(tokenRates, rates.DealBuyBack, FiatName)
+				if !ok || !amount.Equal(amount.New(1), amount.FromBytes(buyBack.Min)) {
+					return fmt.Errorf("rate %s of %s isn't set", rates.DealBuyBack, FiatName)
+				}
+				return nil
+			})
+		})
+
+		t.WithNewStep("Read rates from metadata", func(sCtx provider.StepCtx) {
+			tokenRates, err := rates.Read(ctx, client, "cc")
+			sCtx.Require().NoError(err)
+
+			var ok bool
+			buyRate, ok = rates.Find(tokenRates, rates.DealBuyToken, FiatName)
+			sCtx.Require().True(ok)
+			sCtx.Assert().True(amount.Equal(rates.Rate(2, 1), amount.FromBytes(buyRate.Rate)))
+			sCtx.Assert().True(amount.Equal(amount.New(10), amount.FromBytes(buyRate.Min)))
+			sCtx.Assert().True(amount.Equal(amount.New(100), amount.FromBytes(buyRate.Max)))
+
+			buyBackRate, ok = rates.Find(tokenRates, rates.DealBuyBack, FiatName)
+			sCtx.Require().True(ok)
+			sCtx.Assert().True(amount.Equal(rates.Rate(3, 2), amount.FromBytes(buyBackRate.Rate)))
+		})
+
+		for _, value := range []int64{50, 10, 100} {
+			t.WithNewStep("Buy CC token inside limits: "+amount.String(amount.New(value)), func(sCtx provider.StepCtx) {
+				deal(sCtx, buyRate, value)
+			})
+		}
+
+		for _, value := range []int64{9, 101} {
+			t.WithNewStep("Buy CC token outside of limits is rejected: "+amount.String(amount.New(value)), func(sCtx provider.StepCtx) {
+				_, err := rates.Price(buyRate, amount.New(value))
+				sCtx.Assert().True(errors.Is(err, utils.ErrAmountOutOfLimits))
+
+				resp, err := rates.BuyToken(ctx, client, buyer, "cc", FiatName, amount.New(value))
+				sCtx.Require().NoError(err)
+				utils.RequireTxFailedWith(ctx, sCtx, client, "cc", resp.TransactionID, http.StatusInternalServerError, utils.ErrAmountOutOfLimits)
+
+				after := balancesOf(sCtx)
+				sCtx.Assert().True(amount.Equal(balances.Token, after.Token))
+				sCtx.Assert().True(amount.Equal(balances.Currency, after.Currency))
+			})
+		}
+
+		t.WithNewStep("Buy back CC token for FIAT", func(sCtx provider.StepCtx) {
+			deal(sCtx, buyBackRate, 20)
+			sCtx.Assert().True(amount.Equal(amount.New(140), balances.Token))
+			sCtx.Assert().True(amount.Equal(amount.New(710), balances.Currency))
+		})
+
+		t.WithNewStep("buyToken fails after rate is deleted", func(sCtx provider.StepCtx) {
+			resp, err := rates.DeleteRate(ctx, client, issuer, "cc", rates.DealBuyToken, FiatName)
+			sCtx.Require().NoError(err)
+			utils.RequireTxSucceeded(ctx, sCtx, client, "cc", resp.TransactionID)
+
+			waitRates(sCtx, func(tokenRates []*pb.TokenRate) error {
+				if _, ok := rates.Find(tokenRates, rates.DealBuyToken, FiatName); ok {
+					return fmt.Errorf("rate %s of %s isn't deleted", rates.DealBuyToken, FiatName)
+				}
+				return nil
+			})
+
+			resp, err = rates.BuyToken(ctx, client, buyer, "cc", FiatName, amount.New(50))
+			sCtx.Require().NoError(err)
+			utils.RequireTxFailedWith(ctx, sCtx, client, "cc", resp.TransactionID, http.StatusInternalServerError, nil)
+
+			after := balancesOf(sCtx)
+			sCtx.Assert().True(amount.Equal(balances.Token, after.Token))
+			sCtx.Assert().True(amount.Equal(balances.Currency, after.Currency))
+		})
+	})
+}
@@ -0,0 +1,149 @@
+// Package rates - rates of token chaincode for buyToken and buyBack deals.
+// Issuer sets rate of currency with Decimals and min and max limits of amount, buyer pays price
+// amount * rate / 10^Decimals in allowed balance of currency. Calculator derives expected price and balances from rate
+package rates
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+const (
+	// SetRateFn - signed method of issuer to set rate: deal type, currency, rate
+	SetRateFn = "setRate"
+	// SetLimitsFn - signed method of issuer to set limits of rate: deal type, currency, min, max
+	SetLimitsFn = "setLimits"
+	// DeleteRateFn - signed method of issuer to delete rate: deal type, currency
+	DeleteRateFn = "deleteRate"
+	// BuyTokenFn - signed method to buy token for currency: amount, currency
+	BuyTokenFn = "buyToken"
+	// BuyBackFn - signed method to sell token back for currency: amount, currency
+	BuyBackFn = "buyBack"
+
+	// DealBuyToken - deal type of rate used by buyToken
+	DealBuyToken = "buyToken"
+	// DealBuyBack - deal type of rate used by buyBack
+	DealBuyBack = "buyBack"
+
+	// Decimals - decimals of rate, 100000000 is rate 1
+	Decimals = 8
+)
+
+// Read - rates of token from metadata of channel, see utils.Client.TokenConfig
+func Read(ctx context.Context, client *utils.Client, channel string) ([]*pb.TokenRate, error) {
+	_, token, err := client.TokenConfig(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	return token.Rates, nil
+}
+
+// Find - rate of deal type and currency
+func Find(rates []*pb.TokenRate, dealType, currency string) (*pb.TokenRate, bool) {
+	for _, rate := range rates {
+		if rate.DealType == dealType && strings.EqualFold(rate.Currency, currency) {
+			return rate, true
+		}
+	}
+	return nil, false
+}
+
+// Rate - rate from decimal number, example Rate(3, 2) is 1.5
+func Rate(numerator, denominator int64) *big.Int {
+	rate := new(big.Int).Mul(big.NewInt(numerator), one())
+	return rate.Div(rate, big.NewInt(denominator))
+}
+
+// CheckLimits - value must be not less than min and not greater than max if max is positive,
+// error is utils.ErrAmountOutOfLimits otherwise
+func CheckLimits(rate *pb.TokenRate, value *big.Int) error {
+	lower, upper := amount.FromBytes(rate.Min), amount.FromBytes(rate.Max)
+	if amount.Cmp(value, lower) < 0 || (upper.Sign() > 0 && amount.Cmp(value, upper) > 0) {
+		return fmt.Errorf("%s %s: amount %s, min %s, max %s: %w",
+			rate.DealType, rate.Currency, amount.String(value), lower, upper, utils.ErrAmountOutOfLimits)
+	}
+	return nil
+}
+
+// Price - price of value in currency of rate: value * rate / 10^Decimals, value must be inside limits
+func Price(rate *pb.TokenRate, value *big.Int) (*big.Int, error) {
+	if err := CheckLimits(rate, value); err != nil {
+		return nil, err
+	}
+	price := amount.Mul(value, amount.FromBytes(rate.Rate))
+	return price.Div(price, one()), nil
+}
+
+// Balances - balances of buyer in token and allowed balance of currency
+type Balances struct {
+	Token    *big.Int
+	Currency *big.Int
+}
+
+// Deal - balances of buyer after buyToken or buyBack of value by rate. Buyer pays price for buyToken
+// and receives it for buyBack, error is utils.ErrInsufficientFunds if buyer can't pay
+func Deal(rate *pb.TokenRate, before Balances, value *big.Int) (Balances, error) {
+	price, err := Price(rate, value)
+	if err != nil {
+		return Balances{}, err
+	}
+
+	switch rate.DealType {
+	case DealBuyToken:
+		if amount.Cmp(before.Currency, price) < 0 {
+			return Balances{}, fmt.Errorf("price %s %s: %w", price, rate.Currency, utils.ErrInsufficientFunds)
+		}
+		return Balances{Token: amount.Add(before.Token, value), Currency: amount.Sub(before.Currency, price)}, nil
+	case DealBuyBack:
+		if amount.Cmp(before.Token, value) < 0 {
+			return Balances{}, fmt.Errorf("amount %s: %w", amount.String(value), utils.ErrInsufficientFunds)
+		}
+		return Balances{Token: amount.Sub(before.Token, value), Currency: amount.Add(before.Currency, price)}, nil
+	default:
+		return Balances{}, fmt.Errorf("unknown deal type %s", rate.DealType)
+	}
+}
+
+// SetRate - invoke setRate in channel signed by issuer
+func SetRate(ctx context.Context, client *utils.Client, issuer *fixtures.Identity, channel, dealType, currency string, rate *big.Int) (*utils.Response, error) {
+	return invoke(ctx, client, issuer, channel, SetRateFn, dealType, strings.ToUpper(currency), amount.String(rate))
+}
+
+// SetLimits - invoke setLimits in channel signed by issuer, zero max means no upper limit
+func SetLimits(ctx context.Context, client *utils.Client, issuer *fixtures.Identity, channel, dealType, currency string, lower, upper *big.Int) (*utils.Response, error) {
+	return invoke(ctx, client, issuer, channel, SetLimitsFn, dealType, strings.ToUpper(currency), amount.String(lower), amount.String(upper))
+}
+
+// DeleteRate - invoke deleteRate in channel signed by issuer
+func DeleteRate(ctx context.Context, client *utils.Client, issuer *fixtures.Identity, channel, dealType, currency string) (*utils.Response, error) {
+	return invoke(ctx, client, issuer, channel, DeleteRateFn, dealType, strings.ToUpper(currency))
+}
+
+// BuyToken - invoke buyToken in channel signed by buyer, price is paid in allowed balance of currency
+func BuyToken(ctx context.Context, client *utils.Client, buyer *fixtures.Identity, channel, currency string, value *big.Int) (*utils.Response, error) {
+	return invoke(ctx, client, buyer, channel, BuyTokenFn, amount.String(value), strings.ToUpper(currency))
+}
+
+// BuyBack - invoke buyBack in channel signed by seller, price is received in allowed balance of currency
+func BuyBack(ctx context.Context, client *utils.Client, seller *fixtures.Identity, channel, currency string, value *big.Int) (*utils.Response, error) {
+	return invoke(ctx, client, seller, channel, BuyBackFn, amount.String(value), strings.ToUpper(currency))
+}
+
+func invoke(ctx context.Context, client *utils.Client, signer *fixtures.Identity, channel, method string, args ...string) (*utils.Response, error) {
+	signedArgs, err := signer.Sign(channel, channel, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return client.Invoke(ctx, channel, method, signedArgs...)
+}
+
+func one() *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(Decimals), nil)
+}
@@ -0,0 +1,203 @@
+// Package robot - simulator of batch robot of foundation library.
+// Robot collects pending transactions of channel with swaps and swap keys delivered from other channels into pb.Batch,
+// submits it by batchExecute and delivers swaps started by the batch to their destination channels.
+// Real environment accepts batchExecute only from robot, so simulator is used with fakeproxy.WithManualBatch
+package robot
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+const (
+	// BatchExecuteFn - method of token chaincode to execute pb.Batch
+	BatchExecuteFn = "batchExecute"
+
+	aclChaincode = "acl"
+)
+
+// directMethods - methods executed by chaincode without batch, swap key of them is delivered to source channel
+var directMethods = map[string]bool{
+	"swapDone":      true,
+	"multiSwapDone": true,
+}
+
+// Robot - collects pending batch items by channel, is safe for concurrent use
+type Robot struct {
+	mu sync.Mutex
+	// txIDs - pending transactions by channel
+	txIDs map[string][][]byte
+	// swaps - swaps by destination channel
+	swaps      map[string][]*pb.Swap
+	multiSwaps map[string][]*pb.MultiSwap
+	// keys - swap keys by source channel
+	keys          map[string][]*pb.SwapKey
+	multiSwapKeys map[string][]*pb.SwapKey
+	// sources - source channel of swaps and multi swaps delivered by robot, swap keys are delivered there
+	sources map[string]string
+}
+
+// New - robot without pending items
+func New() *Robot {
+	return &Robot{
+		txIDs:         make(map[string][][]byte),
+		swaps:         make(map[string][]*pb.Swap),
+		multiSwaps:    make(map[string][]*pb.MultiSwap),
+		keys:          make(map[string][]*pb.SwapKey),
+		multiSwapKeys: make(map[string][]*pb.SwapKey),
+		sources:       make(map[string]string),
+	}
+}
+
+// Hook - collect transactions invoked by client, add it with utils.WithHook.
+// Swap keys of swapDone and multiSwapDone are collected for swaps delivered by robot
+func (r *Robot) Hook() utils.Hook {
+	return func(_ context.Context, call *utils.Call) {
+		if call.RequestType != "invoke" || call.Err != nil || call.Response == nil {
+			return
+		}
+		channel, fcn := call.Request.ChaincodeID, call.Request.Fcn
+		if channel == aclChaincode || fcn == BatchExecuteFn {
+			return
+		}
+
+		if !directMethods[fcn] {
+			_ = r.AddTx(channel, call.Response.TransactionID)
+			return
+		}
+
+		const keyArgsLen = 2
+		if len(call.Request.Args) != keyArgsLen {
+			return
+		}
+		_ = r.AddKey(fcn == "multiSwapDone", string(call.Request.Args[0]), string(call.Request.Args[1]))
+	}
+}
+
+// AddTx - add transaction invoked in channel to pending transactions
+func (r *Robot) AddTx(channel, txID string) error {
+	id, err := hex.DecodeString(txID)
+	if err != nil {
+		return fmt.Errorf("decode tx id %s: %w", txID, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txIDs[channel] = append(r.txIDs[channel], id)
+	return nil
+}
+
+// AddKey - add swap key to source channel of swap or multi swap delivered by robot
+func (r *Robot) AddKey(multiSwap bool, swapID, key string) error {
+	id, err := hex.DecodeString(swapID)
+	if err != nil {
+		return fmt.Errorf("decode swap id %s: %w", swapID, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	channel, ok := r.sources[swapID]
+	if !ok {
+		return fmt.Errorf("swap %s isn't delivered by robot", swapID)
+	}
+	swapKey := &pb.SwapKey{Id: id, Key: key}
+	if multiSwap {
+		r.multiSwapKeys[channel] = append(r.multiSwapKeys[channel], swapKey)
+	} else {
+		r.keys[channel] = append(r.keys[channel], swapKey)
+	}
+	return nil
+}
+
+// Pending - batch of channel submitted by the next Execute
+func (r *Robot) Pending(channel string) *pb.Batch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &pb.Batch{
+		TxIDs:          r.txIDs[channel],
+		Swaps:          r.swaps[channel],
+		Keys:           r.keys[channel],
+		MultiSwapsKeys: r.multiSwapKeys[channel],
+		MultiSwaps:     r.multiSwaps[channel],
+	}
+}
+
+// Execute - submit pending batch of channel by batchExecute, swaps and multi swaps started by the batch
+// wait for Execute of destination channel. Pending items are kept if batchExecute fails
+func (r *Robot) Execute(ctx context.Context, client *utils.Client, channel string) (*pb.BatchResponse, error) {
+	batch := r.Pending(channel)
+	data, err := proto.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("marshal batch: %w", err)
+	}
+
+	resp, err := client.Invoke(ctx, channel, BatchExecuteFn, string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	batchResponse := &pb.BatchResponse{}
+	if err = proto.Unmarshal(resp.Payload, batchResponse); err != nil {
+		return nil, fmt.Errorf("unmarshal batch response: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txIDs[channel] = r.txIDs[channel][len(batch.TxIDs):]
+	r.swaps[channel] = r.swaps[channel][len(batch.Swaps):]
+	r.keys[channel] = r.keys[channel][len(batch.Keys):]
+	r.multiSwapKeys[channel] = r.multiSwapKeys[channel][len(batch.MultiSwapsKeys):]
+	r.multiSwaps[channel] = r.multiSwaps[channel][len(batch.MultiSwaps):]
+
+	for _, swap := range batchResponse.CreatedSwaps {
+		to := strings.ToLower(swap.To)
+		r.swaps[to] = append(r.swaps[to], swap)
+		r.sources[hex.EncodeToString(swap.Id)] = channel
+	}
+	for _, swap := range batchResponse.CreatedMultiSwap {
+		to := strings.ToLower(swap.To)
+		r.multiSwaps[to] = append(r.multiSwaps[to], swap)
+		r.sources[hex.EncodeToString(swap.Id)] = channel
+	}
+
+	return batchResponse, nil
+}
+
+// TxResponse - response of transaction in batch response, error is *utils.TxError if transaction failed
+func TxResponse(resp *pb.BatchResponse, txID string) (*pb.TxResponse, error) {
+	for _, txResponse := range resp.GetTxResponses() {
+		if hex.EncodeToString(txResponse.Id) != txID {
+			continue
+		}
+		if txResponse.Error != nil {
+			return txResponse, &utils.TxError{
+				TxID:    txID,
+				Method:  txResponse.Method,
+				Code:    txResponse.Error.Code,
+				Message: txResponse.Error.Error,
+			}
+		}
+		return txResponse, nil
+	}
+	return nil, fmt.Errorf("transaction %s not found in batch response", txID)
+}
+
+// SwapErrors - failures of swaps and swap keys in batch response by swap id in hex
+func SwapErrors(resp *pb.BatchResponse) map[string]string {
+	errs := make(map[string]string)
+	for _, responses := range [][]*pb.SwapResponse{resp.GetSwapResponses(), resp.GetSwapKeyResponses()} {
+		for _, swapResponse := range responses {
+			if swapResponse.Error != nil {
+				errs[hex.EncodeToString(swapResponse.Id)] = swapResponse.Error.Error
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,193 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fakeproxy"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/robot"
+	"github.com/tickets-dao/integration/swap"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// startManualBatchProxy - fake hlf proxy service with manual batch mode and client collecting transactions into robot.
+// Real environment accepts batchExecute only from robot, so test is executed only against fake hlf proxy service
+func startManualBatchProxy(t provider.T, r *robot.Robot) *utils.Client {
+	if fake == nil {
+		t.Skip("reason: batchExecute is allowed only for robot in real environment")
+	}
+
+	_, issuerPublicKey, err := utils.GetPrivateKeyFromBase58Check(os.Getenv(utils.EnvFiatIssuerPrivateKey))
+	t.Require().NoError(err)
+
+	proxy := fakeproxy.New(fakeproxy.WithIssuer(issuerPublicKey), fakeproxy.WithManualBatch())
+	t.Cleanup(proxy.Close)
+
+	batchClient, err := utils.NewClient(proxy.URL(), utils.WithHook(r.Hook()))
+	t.Require().NoError(err)
+	return batchClient
+}
+
+// TestRobotBatch - transactions are executed only by batch of robot, failure of transaction is reported in batch response
+func TestRobotBatch(t *testing.T) {
+	runner.Run(t, "robot executes pending transactions by batchExecute", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("Emit and transfer are pending until robot submits batch, batch response contains error of every transaction")
+		t.Tags("positive", "negative", "robot")
+
+		var (
+			ctx         = context.Background()
+			r           = robot.New()
+			batchClient = startManualBatchProxy(t, r)
+
+			issuer, user, recipient *fixtures.Identity
+			emitTxID                string
+		)
+
+		t.WithNewStep("Register users in acl", func(sCtx provider.StepCtx) {
+			var err error
+			issuer, err = fixtures.NewIssuer(ctx, batchClient)
+			sCtx.Require().NoError(err)
+			user, err = fixtures.NewUser(ctx, batchClient)
+			sCtx.Require().NoError(err)
+			recipient, err = fixtures.NewUser(ctx, batchClient)
+			sCtx.Require().NoError(err)
+		})
+
+		t.WithNewStep("Emit FIAT token to user, emission is pending until batch", func(sCtx provider.StepCtx) {
+			signedArgs, err := issuer.Sign("fiat", "fiat", "emit", user.Address, "1")
+			sCtx.Require().NoError(err)
+			resp, err := batchClient.Invoke(ctx, "fiat", "emit", signedArgs...)
+			sCtx.Require().NoError(err)
+			emitTxID = resp.TransactionID
+
+			sCtx.Assert().Len(r.Pending("fiat").TxIDs, 1)
+			balance, err := batchClient.BalanceOf(ctx, "fiat", user.Address)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(amount.IsZero(balance))
+		})
+
+		t.WithNewStep("Execute batch of fiat channel", func(sCtx provider.StepCtx) {
+			resp, err := r.Execute(ctx, batchClient, "fiat")
+			sCtx.Require().NoError(err)
+			_, err = robot.TxResponse(resp, emitTxID)
+			sCtx.Assert().NoError(err)
+			sCtx.Assert().Empty(r.Pending("fiat").TxIDs)
+
+			balance, err := batchClient.BalanceOf(ctx, "fiat", user.Address)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(amount.Equal(amount.New(1), balance))
+		})
+
+		t.WithNewStep("Transfer more than balance, batch response contains error of transaction", func(sCtx provider.StepCtx) {
+			signedArgs, err := user.Sign("fiat", "fiat", "transfer", recipient.Address, "5", "")
+			sCtx.Require().NoError(err)
+			transferResp, err := batchClient.Invoke(ctx, "fiat", "transfer", signedArgs...)
+			sCtx.Require().NoError(err)
+
+			resp, err := r.Execute(ctx, batchClient, "fiat")
+			sCtx.Require().NoError(err)
+			txResponse, err := robot.TxResponse(resp, transferResp.TransactionID)
+			sCtx.Require().Error(err)
+			sCtx.Assert().True(errors.Is(err, utils.ErrInsufficientFunds))
+			sCtx.Assert().Equal("transfer", txResponse.Method)
+
+			balance, err := batchClient.BalanceOf(ctx, "fiat", user.Address)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(amount.Equal(amount.New(1), balance))
+		})
+	})
+}
+
+// TestRobotSwap - swap and swap key are delivered between channels only by batches of robot
+func TestRobotSwap(t *testing.T) {
+	runner.Run(t, "robot delivers swap from fiat to cc and swap key back", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("Swap appears in cc after batches of fiat and cc, swap is removed from fiat after swap key is delivered")
+		t.Tags("positive", "robot", "swap")
+
+		var (
+			ctx         = context.Background()
+			r           = robot.New()
+			batchClient = startManualBatchProxy(t, r)
+
+			user   *fixtures.Identity
+			key    string
+			swapID string
+		)
+
+		t.WithNewStep("Register users and emit FIAT token to user", func(sCtx provider.StepCtx) {
+			issuer, err := fixtures.NewIssuer(ctx, batchClient)
+			sCtx.Require().NoError(err)
+			user, err = fixtures.NewUser(ctx, batchClient)
+			sCtx.Require().NoError(err)
+
+			signedArgs, err := issuer.Sign("fiat", "fiat", "emit", user.Address, "1")
+			sCtx.Require().NoError(err)
+			_, err = batchClient.Invoke(ctx, "fiat", "emit", signedArgs...)
+			sCtx.Require().NoError(err)
+			_, err = r.Execute(ctx, batchClient, "fiat")
+			sCtx.Require().NoError(err)
+		})
+
+		t.WithNewStep("Begin swap, batch of fiat creates swap", func(sCtx provider.StepCtx) {
+			var err error
+			key, err = swap.NewKey()
+			sCtx.Require().NoError(err)
+
+			signedArgs, err := user.Sign("fiat", "fiat", swap.BeginFn, FiatName, "CC", "1", swap.Hash(key))
+			sCtx.Require().NoError(err)
+			beginResp, err := batchClient.Invoke(ctx, "fiat", swap.BeginFn, signedArgs...)
+			sCtx.Require().NoError(err)
+			swapID = beginResp.TransactionID
+
+			resp, err := r.Execute(ctx, batchClient, "fiat")
+			sCtx.Require().NoError(err)
+			_, err = robot.TxResponse(resp, swapID)
+			sCtx.Require().NoError(err)
+			sCtx.Require().Len(resp.CreatedSwaps, 1)
+			sCtx.Assert().NoError(swap.VerifyKey(resp.CreatedSwaps[0], key))
+
+			_, err = swap.Get(ctx, batchClient, "cc", swapID)
+			sCtx.Assert().True(errors.Is(err, utils.ErrSwapNotFound))
+			sCtx.Assert().Len(r.Pending("cc").Swaps, 1)
+		})
+
+		t.WithNewStep("Batch of cc delivers swap, complete swap in cc", func(sCtx provider.StepCtx) {
+			resp, err := r.Execute(ctx, batchClient, "cc")
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Empty(robot.SwapErrors(resp))
+
+			_, err = swap.Get(ctx, batchClient, "cc", swapID)
+			sCtx.Require().NoError(err)
+
+			_, err = batchClient.Invoke(ctx, "cc", swap.DoneFn, swapID, key)
+			sCtx.Require().NoError(err)
+
+			balance, err := batchClient.AllowedBalanceOf(ctx, "cc", user.Address, FiatName)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(amount.Equal(amount.New(1), balance))
+		})
+
+		t.WithNewStep("Swap is removed from fiat only after batch delivers swap key", func(sCtx provider.StepCtx) {
+			_, err := swap.Get(ctx, batchClient, "fiat", swapID)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Len(r.Pending("fiat").Keys, 1)
+
+			resp, err := r.Execute(ctx, batchClient, "fiat")
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Len(resp.SwapKeyResponses, 1)
+			sCtx.Assert().Empty(robot.SwapErrors(resp))
+
+			_, err = swap.Get(ctx, batchClient, "fiat", swapID)
+			sCtx.Assert().True(errors.Is(err, utils.ErrSwapNotFound))
+		})
+	})
+}
@@ -0,0 +1,190 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/acl"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// requireValidators - signers of acl validators from utils.EnvValidatorPrivateKeys, test is skipped without them
+func requireValidators(t provider.T) []utils.Signer {
+	validators, err := utils.ValidatorsFromEnv()
+	t.Require().NoError(err)
+	if len(validators) == 0 {
+		t.Skip("reason: " + utils.EnvValidatorPrivateKeys + " isn't set")
+	}
+	return validators
+}
+
+// TestChangePublicKey - validators replace public key of user, address and balance are kept, old key can't sign
+func TestChangePublicKey(t *testing.T) {
+	runner.Run(t, "change public key of user by validators", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("changePublicKey signed by all validators replaces key of user keeping address, transactions of old key are rejected")
+		t.Tags("positive", "negative", "acl", "key rotation")
+		requireBatchEvents(t)
+
+		var (
+			ctx        = context.Background()
+			aclClient  = acl.NewClient(client)
+			validators = requireValidators(t)
+
+			issuer, user, rotated, recipient *fixtures.Identity
+		)
+
+		t.WithNewStep("Register users and emit FIAT token to user", func(sCtx provider.StepCtx) {
+			var err error
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			user, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			recipient, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+
+			invokeSucceeded(ctx, sCtx, issuer, "fiat", "emit", user.Address, "10")
+			requireBalance(ctx, sCtx, "fiat", user.Address, amount.New(10))
+		})
+
+		t.WithNewStep("changePublicKey without signatures of all validators is rejected", func(sCtx provider.StepCtx) {
+			_, newPublicKey, err := utils.GeneratePrivateAndPublicKey()
+			sCtx.Require().NoError(err)
+			userSigner, err := user.Signer()
+			sCtx.Require().NoError(err)
+
+			for name, signers := range map[string][]utils.Signer{
+				"not all validators":   validators[:len(validators)-1],
+				"user isn't validator": {userSigner},
+			} {
+				if len(signers) == 0 {
+					continue
+				}
+				args, err := utils.SignChangePublicKey(signers, user.Address, "lost key", 1, newPublicKey, utils.NonceAt(time.Now()))
+				sCtx.Require().NoError(err)
+				_, err = client.Invoke(ctx, acl.ChannelName, utils.ChangePublicKeyFn, args...)
+				sCtx.Assert().Error(err, name)
+			}
+
+			_, err = aclClient.CheckKeys(ctx, user.PublicKeyBase58)
+			sCtx.Assert().NoError(err)
+		})
+
+		t.WithNewStep("Validators change public key of user", func(sCtx provider.StepCtx) {
+			var err error
+			rotated, err = fixtures.ChangeKey(ctx, client, validators, user, "lost key", 1)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Equal(user.Address, rotated.Address)
+
+			resp, err := aclClient.CheckKeys(ctx, rotated.PublicKeyBase58)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Equal(user.Address, acl.EncodeAddress(resp.Address.Address))
+			sCtx.Assert().Equal("lost key", resp.Address.Reason)
+			sCtx.Assert().Equal(int32(1), resp.Address.ReasonId)
+			sCtx.Require().NotEmpty(resp.Address.SignedTx)
+			sCtx.Assert().Equal(utils.ChangePublicKeyFn, resp.Address.SignedTx[0])
+
+			_, err = aclClient.CheckKeys(ctx, user.PublicKeyBase58)
+			sCtx.Assert().True(errors.Is(err, utils.ErrPublicKeyNotFound))
+		})
+
+		t.WithNewStep("Old key can't sign transfer", func(sCtx provider.StepCtx) {
+			invokeRejected(ctx, sCtx, user, "fiat", "transfer", utils.ErrPublicKeyNotFound, recipient.Address, "1", "")
+			requireBalance(ctx, sCtx, "fiat", user.Address, amount.New(10))
+		})
+
+		t.WithNewStep("New key signs transfer from the same address", func(sCtx provider.StepCtx) {
+			invokeSucceeded(ctx, sCtx, rotated, "fiat", "transfer", recipient.Address, "4", "")
+			requireBalance(ctx, sCtx, "fiat", rotated.Address, amount.New(6))
+			requireBalance(ctx, sCtx, "fiat", recipient.Address, amount.New(4))
+		})
+	})
+}
+
+// TestChangeMultisigPublicKey - validators replace key of multisig member, multisig keeps address,
+// old member can't sign and new member signs with remaining members
+func TestChangeMultisigPublicKey(t *testing.T) {
+	runner.Run(t, "change public key of multisig member by validators", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("changeMultisigPublicKey replaces member key keeping multisig address and N, signatures of old key are rejected")
+		t.Tags("positive", "negative", "acl", "multisig", "key rotation")
+		requireBatchEvents(t)
+
+		var (
+			ctx        = context.Background()
+			aclClient  = acl.NewClient(client)
+			validators = requireValidators(t)
+
+			issuer, user, member1, member2, member3, rotated *fixtures.Identity
+			multisig                                         *fixtures.Multisig
+			oldPolicy                                        *pb.SignaturePolicy
+		)
+
+		t.WithNewStep("Register users and multisig 2 of 3, emit FIAT token to multisig", func(sCtx provider.StepCtx) {
+			var err error
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			user, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+			for _, member := range []**fixtures.Identity{&member1, &member2, &member3} {
+				*member, err = fixtures.NewUser(ctx, client)
+				sCtx.Require().NoError(err)
+			}
+			multisig, err = fixtures.NewMultisig(ctx, client, 2, member1, member2, member3)
+			sCtx.Require().NoError(err)
+			oldPolicy = &pb.SignaturePolicy{N: multisig.Policy.N, PubKeys: multisig.Policy.PubKeys}
+
+			invokeSucceeded(ctx, sCtx, issuer, "fiat", "emit", multisig.Address, "2")
+			requireBalance(ctx, sCtx, "fiat", multisig.Address, amount.New(2))
+		})
+
+		t.WithNewStep("Validators replace key of first member", func(sCtx provider.StepCtx) {
+			var err error
+			rotated, err = multisig.ReplaceMember(ctx, client, validators, member1, "compromised key", 2)
+			sCtx.Require().NoError(err)
+
+			resp, err := aclClient.CheckKeys(ctx, utils.MultisigKey(multisig.Policy))
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Equal(multisig.Address, acl.EncodeAddress(resp.Address.Address))
+			policy := resp.Address.SignaturePolicy
+			sCtx.Require().NotNil(policy)
+			sCtx.Assert().Equal(uint32(2), policy.N)
+			sCtx.Require().NotEmpty(policy.ReplaceKeysSignedTx)
+			sCtx.Assert().Equal(utils.ChangeMultisigPublicKeyFn, policy.ReplaceKeysSignedTx[0])
+			for _, key := range policy.PubKeys {
+				sCtx.Assert().False(bytes.Equal(member1.PublicKey, key))
+			}
+
+			_, err = aclClient.CheckKeys(ctx, utils.MultisigKey(oldPolicy))
+			sCtx.Assert().True(errors.Is(err, utils.ErrPublicKeyNotFound))
+		})
+
+		t.WithNewStep("Signatures of old key are rejected", func(sCtx provider.StepCtx) {
+			signers, err := fixtures.Signers(member1, member2)
+			sCtx.Require().NoError(err)
+			tx, err := utils.SignMultisigTx(oldPolicy, signers,
+				"fiat", "fiat", "transfer", utils.NonceAt(time.Now()), user.Address, "1", "")
+			sCtx.Require().NoError(err)
+			_, err = client.Invoke(ctx, "fiat", "transfer", tx.Args()...)
+			sCtx.Assert().True(errors.Is(err, utils.ErrPublicKeyNotFound))
+		})
+
+		t.WithNewStep("New member signs transfer with second member", func(sCtx provider.StepCtx) {
+			signedArgs, err := multisig.Sign([]*fixtures.Identity{rotated, member2}, "fiat", "fiat", "transfer", user.Address, "1", "")
+			sCtx.Require().NoError(err)
+			resp, err := client.Invoke(ctx, "fiat", "transfer", signedArgs...)
+			sCtx.Require().NoError(err)
+			utils.RequireTxSucceeded(ctx, sCtx, client, "fiat", resp.TransactionID)
+			requireBalance(ctx, sCtx, "fiat", multisig.Address, amount.New(1))
+		})
+	})
+}
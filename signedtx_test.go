@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	"github.com/tickets-dao/integration/utils"
+)
+
+// TestSignedTxTampered - emit amount to user, change amount in signed arguments and check that chaincode rejects them
+func TestSignedTxTampered(t *testing.T) {
+	runner.Run(t, "Emission of `fiat` token with tampered signed arguments", func(t provider.T) {
+		t.Severity(allure.CRITICAL)
+		t.Description("Testing that chaincode rejects signed arguments changed after signing")
+		t.Tags("negative", "signature")
+
+		var (
+			ctx = context.Background()
+
+			issuer, user *fixtures.Identity
+		)
+
+		t.WithNewStep("Register users in `acl` chaincode", func(sCtx provider.StepCtx) {
+			sCtx.WithNewAsyncStep("Register issuer with crypto from env", func(sCtx provider.StepCtx) {
+				var err error
+				issuer, err = fixtures.NewIssuer(ctx, client)
+				sCtx.Require().NoError(err)
+			})
+
+			sCtx.WithNewAsyncStep("Register user", func(sCtx provider.StepCtx) {
+				var err error
+				user, err = fixtures.NewUser(ctx, client)
+				sCtx.Require().NoError(err)
+			})
+		})
+
+		t.WithNewStep("Emit FIAT token to user", func(sCtx provider.StepCtx) {
+			var (
+				emitAmount = "1"
+				tx         *utils.SignedTx
+				err        error
+			)
+
+			sCtx.WithNewStep("Sign arguments and parse them back", func(sCtx provider.StepCtx) {
+				args, err := issuer.Sign("fiat", "fiat", "emit", user.Address, emitAmount)
+				sCtx.Require().NoError(err)
+
+				tx, err = utils.ParseSignedArgs("emit", args)
+				sCtx.Require().NoError(err)
+				sCtx.Assert().Equal([]string{user.Address, emitAmount}, tx.MethodArgs)
+				sCtx.Assert().Equal(issuer.PublicKey, tx.PublicKey)
+				sCtx.Assert().Equal(args, tx.Args())
+			})
+
+			sCtx.WithNewStep("Invoke fiat chaincode with tampered amount", func(sCtx provider.StepCtx) {
+				tx.MethodArgs[1] = "1000"
+				sCtx.Assert().Error(tx.Verify())
+
+				_, err = client.Invoke(ctx, "fiat", "emit", tx.Args()...)
+				sCtx.Assert().True(errors.Is(err, utils.ErrIncorrectSignature))
+			})
+
+			sCtx.WithNewStep("Invoke fiat chaincode with arguments signed again", func(sCtx provider.StepCtx) {
+				tx.MethodArgs[1] = emitAmount
+				tx.Nonce = utils.DefaultNonceSource.Next(issuer.PublicKey)
+				signer, err := issuer.Signer()
+				sCtx.Require().NoError(err)
+				sCtx.Require().NoError(tx.Sign(signer))
+
+				_, err = client.Invoke(ctx, "fiat", "emit", tx.Args()...)
+				sCtx.Assert().NoError(err)
+			})
+
+			sCtx.WithNewStep("Check balance of user after emission", func(sCtx provider.StepCtx) {
+				_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+					return client.Query(ctx, "fiat", "balanceOf", user.Address)
+				}, utils.AmountEquals(amount.New(1)))
+				sCtx.Assert().NoError(err)
+			})
+		})
+	})
+}
@@ -0,0 +1,47 @@
+package swap
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/tickets-dao/integration/utils"
+	"golang.org/x/crypto/sha3"
+)
+
+// keyLength - number of random bytes of swap key generated by NewKey
+const keyLength = 32
+
+// Hashed - on-chain swap with hash of swap key, pb.Swap and pb.MultiSwap
+type Hashed interface {
+	GetHash() []byte
+}
+
+// NewKey - random swap key in hex, every swap should have its own key
+func NewKey() (string, error) {
+	key := make([]byte, keyLength)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("generate swap key: %w", err)
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// Hash - hash of swap key in hex, argument of swapBegin and multiSwapBegin
+func Hash(key string) string {
+	return hex.EncodeToString(HashBytes(key))
+}
+
+// HashBytes - sha3 hash of swap key, value of Swap.Hash and MultiSwap.Hash
+func HashBytes(key string) []byte {
+	hash := sha3.Sum256([]byte(key))
+	return hash[:]
+}
+
+// VerifyKey - check that key completes swap like swapDone does, error is utils.ErrIncorrectSwapKey otherwise
+func VerifyKey(swap Hashed, key string) error {
+	if !bytes.Equal(swap.GetHash(), HashBytes(key)) {
+		return fmt.Errorf("hash %s: %w", hex.EncodeToString(swap.GetHash()), utils.ErrIncorrectSwapKey)
+	}
+	return nil
+}
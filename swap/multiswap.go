@@ -0,0 +1,179 @@
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+const (
+	// MultiBeginFn - signed method of source channel to start multi swap
+	MultiBeginFn = "multiSwapBegin"
+	// MultiGetFn - query of multi swap by id in any channel
+	MultiGetFn = "multiSwapGet"
+	// MultiDoneFn - method of destination channel to complete multi swap with key
+	MultiDoneFn = "multiSwapDone"
+)
+
+// multiSwapAssets - assets argument of multiSwapBegin in format {"Assets":[{"group":"FIAT","amount":"1"}]}
+type multiSwapAssets struct {
+	Assets []multiSwapAsset `json:"Assets"`
+}
+
+type multiSwapAsset struct {
+	Group  string `json:"group"`
+	Amount string `json:"amount"`
+}
+
+// MultiSwapBuilder - builds arguments of multiSwapBegin, the first error of builder methods is returned by Args
+type MultiSwapBuilder struct {
+	token     string
+	toChannel string
+	key       string
+	assets    []*pb.Asset
+	err       error
+}
+
+// NewMultiSwap - multi swap of token with random key generated by NewKey, example token 'INDUSTRIAL'
+func NewMultiSwap(token string) *MultiSwapBuilder {
+	b := &MultiSwapBuilder{
+		token: strings.ToUpper(token),
+	}
+	b.key, b.err = NewKey()
+	if token == "" {
+		b.err = errors.New("token can't be empty")
+	}
+	return b
+}
+
+// Asset - add amount of group, group is token name for plain token or group of industrial token, example '202010'.
+// Amount must be positive and every group can be added once
+func (b *MultiSwapBuilder) Asset(group string, value *big.Int) *MultiSwapBuilder {
+	switch {
+	case b.err != nil:
+	case group == "":
+		b.err = errors.New("group can't be empty")
+	case value == nil || value.Sign() <= 0:
+		b.err = fmt.Errorf("amount of group %s must be positive, got %s", group, amount.String(value))
+	default:
+		for _, asset := range b.assets {
+			if asset.Group == group {
+				b.err = fmt.Errorf("group %s is already added", group)
+				return b
+			}
+		}
+		b.assets = append(b.assets, &pb.Asset{Group: group, Amount: amount.ToBytes(value)})
+	}
+	return b
+}
+
+// To - destination channel, example 'cc'
+func (b *MultiSwapBuilder) To(channel string) *MultiSwapBuilder {
+	b.toChannel = strings.ToUpper(channel)
+	return b
+}
+
+// Key - swap key instead of random one
+func (b *MultiSwapBuilder) Key(key string) *MultiSwapBuilder {
+	b.key = key
+	return b
+}
+
+// SwapKey - swap key to complete multi swap with MultiSwapDone
+func (b *MultiSwapBuilder) SwapKey() string {
+	return b.key
+}
+
+// Assets - assets of multi swap added by Asset
+func (b *MultiSwapBuilder) Assets() []*pb.Asset {
+	return b.assets
+}
+
+// AssetsJSON - assets argument of multiSwapBegin
+func (b *MultiSwapBuilder) AssetsJSON() (string, error) {
+	if err := b.validate(); err != nil {
+		return "", err
+	}
+
+	assets := multiSwapAssets{Assets: make([]multiSwapAsset, len(b.assets))}
+	for i, asset := range b.assets {
+		assets.Assets[i] = multiSwapAsset{Group: asset.Group, Amount: amount.FromBytes(asset.Amount).String()}
+	}
+	data, err := json.Marshal(assets)
+	if err != nil {
+		return "", fmt.Errorf("json marshal assets: %w", err)
+	}
+	return string(data), nil
+}
+
+// Args - unsigned arguments of multiSwapBegin: token, assets, channel to, hash of swap key
+func (b *MultiSwapBuilder) Args() ([]string, error) {
+	assets, err := b.AssetsJSON()
+	if err != nil {
+		return nil, err
+	}
+	return []string{b.token, assets, b.toChannel, Hash(b.key)}, nil
+}
+
+// Sign - arguments of multiSwapBegin signed by owner of assets in source channel fromChannel
+func (b *MultiSwapBuilder) Sign(from *fixtures.Identity, fromChannel string) ([]string, error) {
+	args, err := b.Args()
+	if err != nil {
+		return nil, err
+	}
+	return from.Sign(fromChannel, fromChannel, MultiBeginFn, args...)
+}
+
+// Begin - invoke multiSwapBegin in source channel fromChannel, returns multi swap id
+func (b *MultiSwapBuilder) Begin(ctx context.Context, client *utils.Client, from *fixtures.Identity, fromChannel string) (string, error) {
+	args, err := b.Sign(from, fromChannel)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Invoke(ctx, fromChannel, MultiBeginFn, args...)
+	if err != nil {
+		return "", err
+	}
+	return resp.TransactionID, nil
+}
+
+func (b *MultiSwapBuilder) validate() error {
+	if b.err != nil {
+		return b.err
+	}
+	if len(b.assets) == 0 {
+		return errors.New("assets can't be empty")
+	}
+	if b.toChannel == "" {
+		return errors.New("destination channel is required")
+	}
+	return nil
+}
+
+// MultiSwapGet - multi swap by id in channel, error is utils.ErrSwapNotFound if multi swap doesn't exist
+func MultiSwapGet(ctx context.Context, client *utils.Client, channel, id string) (*pb.MultiSwap, error) {
+	resp, err := client.Query(ctx, channel, MultiGetFn, id)
+	if err != nil {
+		return nil, err
+	}
+
+	swap := &pb.MultiSwap{}
+	if err = json.Unmarshal(resp.Payload, swap); err != nil {
+		return nil, fmt.Errorf("json unmarshal multi swap: %w", err)
+	}
+	return swap, nil
+}
+
+// MultiSwapDone - complete multi swap in destination channel with swap key
+func MultiSwapDone(ctx context.Context, client *utils.Client, channel, id, key string) error {
+	_, err := client.Invoke(ctx, channel, MultiDoneFn, id, key)
+	return err
+}
@@ -0,0 +1,238 @@
+// Package swap - transfer of token between channels by swap of foundation library.
+// Swap is started in source channel by owner with hash of secret key, robot copies it to destination channel,
+// anyone who knows the key completes it in destination channel
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/utils"
+)
+
+const (
+	// BeginFn - signed method of source channel to start swap
+	BeginFn = "swapBegin"
+	// GetFn - query of swap by id in any channel
+	GetFn = "swapGet"
+	// DoneFn - method of destination channel to complete swap with key
+	DoneFn = "swapDone"
+	// CancelFn - signed method of source channel to cancel swap and return amount to owner
+	CancelFn = "swapCancel"
+)
+
+// State - state of swap in Swapper.Transfer
+type State string
+
+const (
+	// StateBegin - swapBegin is invoked in source channel
+	StateBegin State = "begin"
+	// StateVisible - swap is waited for in source and destination channels
+	StateVisible State = "visible"
+	// StateDone - swapDone is invoked in destination channel
+	StateDone State = "done"
+	// StateCompleted - swap is waited to be removed from destination channel
+	StateCompleted State = "completed"
+	// StateCancelled - swap failed before swapDone is accepted and swapCancel is invoked in source channel
+	StateCancelled State = "cancelled"
+)
+
+// Result - swap executed by Swapper.Transfer
+type Result struct {
+	// ID - swap id, transaction id of swapBegin
+	ID string
+	// Key - swap key of swap
+	Key string
+	// State - the last state reached, StateCompleted if swap is successful
+	State State
+	// From - swap in source channel, nil if it wasn't visible
+	From *pb.Swap
+	// To - swap in destination channel, nil if it wasn't visible
+	To *pb.Swap
+}
+
+// Option - configures Swapper
+type Option func(s *Swapper)
+
+// WithKey - the same swap key for every swap instead of random key generated by NewKey
+func WithKey(key string) Option {
+	return func(s *Swapper) {
+		s.key = key
+	}
+}
+
+// WithStateTimeout - time limit of state, StateCancelled limits swapCancel
+func WithStateTimeout(state State, timeout time.Duration) Option {
+	return func(s *Swapper) {
+		s.timeouts[state] = timeout
+	}
+}
+
+// WithoutCancel - don't invoke swapCancel on failure, swap is left as is
+func WithoutCancel() Option {
+	return func(s *Swapper) {
+		s.cancel = false
+	}
+}
+
+// Swapper - executes swaps through hlf proxy service
+type Swapper struct {
+	client   *utils.Client
+	key      string
+	timeouts map[State]time.Duration
+	cancel   bool
+}
+
+// NewSwapper - create swapper with random key for every swap, invoke states are limited by utils.InvokeTimeout
+// and wait states by utils.WaitTimeout
+func NewSwapper(client *utils.Client, opts ...Option) *Swapper {
+	s := &Swapper{
+		client: client,
+		timeouts: map[State]time.Duration{
+			StateBegin:     utils.InvokeTimeout,
+			StateVisible:   utils.WaitTimeout,
+			StateDone:      utils.InvokeTimeout,
+			StateCompleted: utils.WaitTimeout,
+			StateCancelled: utils.InvokeTimeout,
+		},
+		cancel: true,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Transfer - swap amount of token owned by from from channel fromChannel to channel toChannel.
+// States are executed in order StateBegin, StateVisible, StateDone, StateCompleted.
+// If StateVisible or StateDone fails, swap is cancelled in source channel and result has StateCancelled.
+// Failed StateCompleted isn't cancelled because swapDone is already accepted in destination channel.
+// Example: forward swap Transfer(ctx, user, "FIAT", "fiat", "cc", amount),
+// back swap Transfer(ctx, user, "FIAT", "cc", "fiat", amount)
+func (s *Swapper) Transfer(ctx context.Context, from *fixtures.Identity, token, fromChannel, toChannel string, value *big.Int) (*Result, error) {
+	result := &Result{Key: s.key}
+	if result.Key == "" {
+		var err error
+		if result.Key, err = NewKey(); err != nil {
+			return result, err
+		}
+	}
+
+	steps := []struct {
+		state State
+		run   func(ctx context.Context) error
+	}{
+		{StateBegin, func(ctx context.Context) error {
+			args, err := from.Sign(fromChannel, fromChannel, BeginFn,
+				strings.ToUpper(token), strings.ToUpper(toChannel), amount.String(value), Hash(result.Key))
+			if err != nil {
+				return err
+			}
+			resp, err := s.client.Invoke(ctx, fromChannel, BeginFn, args...)
+			if err != nil {
+				return err
+			}
+			result.ID = resp.TransactionID
+			return nil
+		}},
+		{StateVisible, func(ctx context.Context) error {
+			var err error
+			if result.From, err = s.waitSwap(ctx, fromChannel, result.ID); err != nil {
+				return err
+			}
+			result.To, err = s.waitSwap(ctx, toChannel, result.ID)
+			return err
+		}},
+		{StateDone, func(ctx context.Context) error {
+			_, err := s.client.Invoke(ctx, toChannel, DoneFn, result.ID, result.Key)
+			return err
+		}},
+		{StateCompleted, func(ctx context.Context) error {
+			_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				_, err := Get(ctx, s.client, toChannel, result.ID)
+				if errors.Is(err, utils.ErrSwapNotFound) {
+					return &utils.Response{}, nil
+				}
+				if err == nil {
+					err = fmt.Errorf("swap %s is still in channel %s", result.ID, toChannel)
+				}
+				return nil, err
+			}, nil)
+			return err
+		}},
+	}
+
+	for _, step := range steps {
+		result.State = step.state
+		if err := s.runState(ctx, step.state, step.run); err != nil {
+			err = fmt.Errorf("swap %s state %s: %w", result.ID, step.state, err)
+			if (step.state != StateVisible && step.state != StateDone) || !s.cancel {
+				return result, err
+			}
+			return result, s.cancelSwap(from, fromChannel, result, err)
+		}
+	}
+
+	return result, nil
+}
+
+// runState - run state with its time limit
+func (s *Swapper) runState(ctx context.Context, state State, run func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeouts[state])
+	defer cancel()
+	return run(ctx)
+}
+
+// cancelSwap - invoke swapCancel in source channel, cause is returned with cancel failure if any.
+// Cancel isn't limited by ctx of Transfer because it is often expired on failure
+func (s *Swapper) cancelSwap(from *fixtures.Identity, fromChannel string, result *Result, cause error) error {
+	result.State = StateCancelled
+	err := s.runState(context.Background(), StateCancelled, func(ctx context.Context) error {
+		args, err := from.Sign(fromChannel, fromChannel, CancelFn, result.ID)
+		if err != nil {
+			return err
+		}
+		_, err = s.client.Invoke(ctx, fromChannel, CancelFn, args...)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%w, cancel swap: %v", cause, err)
+	}
+	return cause
+}
+
+// waitSwap - wait until swap appears in channel
+func (s *Swapper) waitSwap(ctx context.Context, channel, id string) (*pb.Swap, error) {
+	var swap *pb.Swap
+	_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+		var err error
+		swap, err = Get(ctx, s.client, channel, id)
+		return &utils.Response{}, err
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("wait swap in %s: %w", channel, err)
+	}
+	return swap, nil
+}
+
+// Get - swap by id in channel, error is utils.ErrSwapNotFound if swap doesn't exist
+func Get(ctx context.Context, client *utils.Client, channel, id string) (*pb.Swap, error) {
+	resp, err := client.Query(ctx, channel, GetFn, id)
+	if err != nil {
+		return nil, err
+	}
+
+	swap := &pb.Swap{}
+	if err = json.Unmarshal(resp.Payload, swap); err != nil {
+		return nil, fmt.Errorf("json unmarshal swap: %w", err)
+	}
+	return swap, nil
+}
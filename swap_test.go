@@ -2,236 +2,311 @@ package integration
 
 import (
 	"context"
-	"os"
-	"strings"
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
-	"github.com/btcsuite/btcutil/base58"
 	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/ozontech/allure-go/pkg/framework/runner"
-	"github.com/stretchr/testify/assert"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
+	pb "github.com/tickets-dao/integration/proto"
+	"github.com/tickets-dao/integration/swap"
 	"github.com/tickets-dao/integration/utils"
-	"golang.org/x/crypto/ed25519"
-)
-
-const (
-	DefaultSwapHash = "7d4e3eec80026719639ed4dba68916eb94c7a49a053e05c8f9578fe4e5a3d7ea"
-	DefaultSwapKey  = "12345"
 )
 
 // TestSwap - create user, emit amount to fiat, swap amount from fiat channel to cc channel, check amount is moved
 func TestSwap(t *testing.T) {
-	// skipped due to refactoring
 	runner.Run(t, "swap token from fiat to cc and swap back", func(t provider.T) {
 		ctx := context.Background()
 		t.Severity(allure.BLOCKER)
 		t.Description("Acceptance of emitting amount to fiat and swap amount from fiat channel to cc channel")
 		t.Tags("positive", "swap")
 
-		issuerPrivateKey, issuerPublicKey, err := utils.GetPrivateKeyFromBase58Check(os.Getenv(utils.EnvFiatIssuerPrivateKey))
-		assert.NoError(t.RealT(), err)
+		var (
+			issuer, user *fixtures.Identity
+			swapper      = swap.NewSwapper(client)
+			swapAmount   = amount.New(1)
+			err          error
+		)
 
-		issuerEd25519PublicKeyBase58 := base58.Encode(issuerPublicKey)
-		t.WithNewStep("addUser. Try to add issuer user in acl, no handle err because issuer can exist", func(sCtx provider.StepCtx) {
-			_, err = utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-				os.Getenv(utils.EnvHlfProxyAuthToken), "acl", "addUser", issuerEd25519PublicKeyBase58, "test", "testuser", "true")
-			sCtx.Assert().True(err == nil || strings.Contains(err.Error(), "already exists"))
+		t.WithNewStep("Register issuer with crypto from env in `acl` chaincode", func(sCtx provider.StepCtx) {
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
 		})
 
-		t.WithNewStep("Check issuer public key after adding", func(sCtx provider.StepCtx) {
-			_, err = utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-				os.Getenv(utils.EnvHlfProxyAuthToken), "acl", "checkKeys", issuerEd25519PublicKeyBase58)
-			sCtx.Assert().NoError(err)
-		})
-
-		var userPublicKeyStr, userAddress string
-		var userPrivateKey ed25519.PrivateKey
-		var userPublicKey ed25519.PublicKey
-
-		t.WithNewStep("Adding user with method `addUser` of chaincode `acl`", func(sCtx provider.StepCtx) {
-			sCtx.WithNewStep("Creating cryptos for new user", func(sCtx provider.StepCtx) {
-				userPrivateKey, userPublicKey, err = utils.GeneratePrivateAndPublicKey()
-				t.Assert().NoError(err)
-				userPublicKeyStr = base58.Encode(userPublicKey)
-				userAddress, err = utils.GetAddressByPublicKey(userPublicKey)
-			})
-
-			sCtx.WithNewStep("Add user to chaincode `acl` by invoking method `addUser`", func(sCtx provider.StepCtx) {
-				_, err = utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "acl", "addUser", userPublicKeyStr, "test", "testuser", "true")
-				sCtx.Assert().NoError(err)
-			})
-
-			sCtx.WithNewStep("Check user public key", func(sCtx provider.StepCtx) {
-				_, err = utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "acl", "checkKeys", userPublicKeyStr)
-				sCtx.Assert().NoError(err)
-			})
+		t.WithNewStep("Register user in `acl` chaincode", func(sCtx provider.StepCtx) {
+			user, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
 		})
 
 		t.WithNewStep("Emission of FIAT token to recently added user", func(sCtx provider.StepCtx) {
-			var (
-				amount     = "1"
-				signedArgs []string
-			)
+			var signedArgs []string
 			sCtx.WithNewStep("Sign arguments before sending to chaincode", func(sCtx provider.StepCtx) {
-				signedArgs, err = utils.Sign(issuerPrivateKey, issuerPublicKey, "fiat", "fiat", "emit", []string{userAddress, amount})
+				signedArgs, err = issuer.Sign("fiat", "fiat", "emit", user.Address, amount.String(swapAmount))
 				t.Assert().NoError(err)
 			})
 
 			sCtx.WithNewStep("Emit token", func(sCtx provider.StepCtx) {
-				_, err = utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "fiat", "emit", signedArgs...)
+				_, err = client.Invoke(ctx, "fiat", "emit", signedArgs...)
 				sCtx.Assert().NoError(err)
 			})
 
-			time.Sleep(utils.BatchTransactionTimeout)
 			sCtx.WithNewStep("Check FIAT token balance in `fiat` channel", func(sCtx provider.StepCtx) {
-				resp, err := utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "fiat", "balanceOf", userAddress)
+				_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+					return client.Query(ctx, "fiat", "balanceOf", user.Address)
+				}, utils.AmountEquals(swapAmount))
 				sCtx.Assert().NoError(err)
-				sCtx.Assert().Equal("\"1\"", string(resp.Payload))
 			})
 		})
 
 		t.WithNewStep("Swap token FIAT from `fiat` channel to `cc` channel", func(sCtx provider.StepCtx) {
-			swapFromTokenName := FiatName
-			swapToChannel := "CC"
-			swapAmount := "1"
-
-			swapBeginArgs := []string{swapFromTokenName, swapToChannel, swapAmount, DefaultSwapHash}
-			var signedSwapBeginArgs []string
-			sCtx.WithNewStep("Sign arguments before swap process", func(sCtx provider.StepCtx) {
-				signedSwapBeginArgs, err = utils.Sign(userPrivateKey, userPublicKey, "fiat", "fiat", "swapBegin", swapBeginArgs)
-				sCtx.Assert().NoError(err)
-			})
+			result, err := swapper.Transfer(ctx, user, FiatName, "fiat", "cc", swapAmount)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Equal(swap.StateCompleted, result.State)
 
-			var swapBeginTxID string
-			sCtx.WithNewStep("Invoke `swapBegin` of `fiat` chaincode", func(sCtx provider.StepCtx) {
-				resp, err := utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "fiat", "swapBegin", signedSwapBeginArgs...)
-				sCtx.Assert().NoError(err)
-				swapBeginTxID = resp.TransactionID
-			})
-
-			time.Sleep(utils.BatchTransactionTimeout)
-			sCtx.WithNewStep("Invoke `swapGet` of `fiat` chaincode", func(sCtx provider.StepCtx) {
-				_, err = utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "cc", "swapGet", swapBeginTxID)
-				sCtx.Assert().NoError(err)
-			})
-
-			sCtx.WithNewStep("Get balance of user by invoking method `balanceOf` of chaincode `cc`", func(sCtx provider.StepCtx) {
-				resp, err := utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "fiat", "balanceOf", userAddress)
-				sCtx.Assert().NoError(err)
-				sCtx.Assert().Equal("\"0\"", string(resp.Payload))
-			})
-
-			sCtx.WithNewStep("Get allowed balance in `cc` channel", func(sCtx provider.StepCtx) {
-				resp, err := utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "cc", "allowedBalanceOf", userAddress, "FIAT")
-				sCtx.Assert().NoError(err)
-				sCtx.Assert().Equal("\"0\"", string(resp.Payload))
-			})
-
-			sCtx.WithNewStep("Stop swapping process", func(sCtx provider.StepCtx) {
-				_, err = utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "cc", "swapDone", swapBeginTxID, DefaultSwapKey)
-				sCtx.Assert().NoError(err)
-			})
+			sCtx.Require().NotNil(result.From)
+			sCtx.Require().NotNil(result.To)
+			sCtx.Assert().Equal(FiatName, result.To.Token)
+			sCtx.Assert().Equal("CC", result.To.To)
+			sCtx.Assert().True(amount.Equal(swapAmount, amount.FromBytes(result.From.Amount)))
 		})
 
-		time.Sleep(utils.BatchTransactionTimeout)
 		t.WithNewStep("Check balances in channels", func(sCtx provider.StepCtx) {
 			sCtx.WithNewAsyncStep("Check balance in `fiat` channel", func(sCtx provider.StepCtx) {
-				resp, err := utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "fiat", "balanceOf", userAddress)
+				_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+					return client.Query(ctx, "fiat", "balanceOf", user.Address)
+				}, utils.AmountEquals(amount.New(0)))
 				sCtx.Assert().NoError(err)
-				sCtx.Assert().Equal("\"0\"", string(resp.Payload))
 			})
 			sCtx.WithNewAsyncStep("Check balance in `cc` channel", func(sCtx provider.StepCtx) {
-				resp, err := utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "cc", "allowedBalanceOf", userAddress, "FIAT")
+				_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+					return client.Query(ctx, "cc", "allowedBalanceOf", user.Address, "FIAT")
+				}, utils.AmountEquals(swapAmount))
 				sCtx.Assert().NoError(err)
-				sCtx.Assert().Equal("\"1\"", string(resp.Payload))
 			})
 		})
 
 		t.WithNewStep("Back swap from cc to fiat", func(sCtx provider.StepCtx) {
-			backSwapFromTokenName := FiatName
-			backSwapToChannel := FiatName
-			backSwapAmount := "1"
-
-			backSwapBeginArgs := []string{backSwapFromTokenName, backSwapToChannel, backSwapAmount, DefaultSwapHash}
-			var signedBackSwapBeginArgs []string
-			sCtx.WithNewStep("Sign arguments before swap process", func(sCtx provider.StepCtx) {
-				signedBackSwapBeginArgs, err = utils.Sign(userPrivateKey, userPublicKey, "cc", "cc", "swapBegin", backSwapBeginArgs)
+			result, err := swapper.Transfer(ctx, user, FiatName, "cc", "fiat", swapAmount)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().Equal(swap.StateCompleted, result.State)
+
+			sCtx.Require().NotNil(result.To)
+			sCtx.Assert().Equal(FiatName, result.To.To)
+		})
+
+		t.WithNewStep("Get balances if channels `fiat` and `cc`", func(sCtx provider.StepCtx) {
+			sCtx.WithNewAsyncStep("Get balance in `fiat` channel", func(sCtx provider.StepCtx) {
+				resp, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+					return client.Query(ctx, "fiat", "balanceOf", user.Address)
+				}, utils.AmountEquals(swapAmount))
 				sCtx.Assert().NoError(err)
+				sCtx.Assert().NotNil(resp)
 			})
-
-			var swapBackBeginTxID string
-			sCtx.WithNewStep("Invoke `swapBegin` method of chaincode `cc`", func(sCtx provider.StepCtx) {
-				resp, err := utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "cc", "swapBegin", signedBackSwapBeginArgs...)
+			sCtx.WithNewAsyncStep("Get allowed balance in `cc` channel", func(sCtx provider.StepCtx) {
+				resp, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+					return client.Query(ctx, "cc", "allowedBalanceOf", user.Address, "fiat")
+				}, utils.AmountEquals(amount.New(0)))
 				sCtx.Assert().NoError(err)
-				swapBackBeginTxID = resp.TransactionID
+				sCtx.Assert().NotNil(resp)
 			})
+		})
+	})
+}
 
-			time.Sleep(utils.BatchTransactionTimeout)
-			sCtx.WithNewStep("Query swaps of chaincodes in channels `fiat` and `cc`", func(sCtx provider.StepCtx) {
-				sCtx.WithNewAsyncStep("Query method `swapGet` of chaincode `fiat`", func(sCtx provider.StepCtx) {
-					_, err = utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-						os.Getenv(utils.EnvHlfProxyAuthToken), "fiat", "swapGet", swapBackBeginTxID)
-					sCtx.Assert().NoError(err)
-				})
-
-				sCtx.WithNewAsyncStep("Query method of chaincode `swapGet` of chaincode `cc` ", func(sCtx provider.StepCtx) {
-					_, err = utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-						os.Getenv(utils.EnvHlfProxyAuthToken), "cc", "swapGet", swapBackBeginTxID)
-					sCtx.Assert().NoError(err)
-				})
-			})
+// TestSwapCancel - swap amount greater than balance can't be cancelled, cancel pending swap,
+// check amount is returned to balance
+func TestSwapCancel(t *testing.T) {
+	runner.Run(t, "swap token from fiat to cc with insufficient balance", func(t provider.T) {
+		ctx := context.Background()
+		t.Severity(allure.CRITICAL)
+		t.Description("Swap rejected in batch doesn't exist and can't be cancelled, pending swap is cancelled in source channel, amount is returned to owner")
+		t.Tags("negative", "swap")
+		requireBatchEvents(t)
 
-			sCtx.WithNewStep("Get balances in certain channels in channels `fiat` and `cc`", func(sCtx provider.StepCtx) {
-				sCtx.WithNewAsyncStep("Query method `swapGet` of chaincode `fiat`", func(sCtx provider.StepCtx) {
-					_, err = utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-						os.Getenv(utils.EnvHlfProxyAuthToken), "fiat", "swapGet", swapBackBeginTxID)
-					sCtx.Assert().NoError(err)
-				})
-
-				sCtx.WithNewAsyncStep("Query method of chaincode `swapGet` of chaincode `cc` ", func(sCtx provider.StepCtx) {
-					_, err = utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-						os.Getenv(utils.EnvHlfProxyAuthToken), "cc", "swapGet", swapBackBeginTxID)
-					sCtx.Assert().NoError(err)
-				})
-			})
+		var (
+			issuer, user *fixtures.Identity
+			err          error
+		)
 
-			sCtx.WithNewStep("Finish swap process with method `swapDone` of chaincode `fiat`", func(sCtx provider.StepCtx) {
-				_, err = utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken), "fiat", "swapDone", swapBackBeginTxID, DefaultSwapKey)
-				sCtx.Assert().NoError(err)
-			})
+		t.WithNewStep("Register users and emit FIAT token to user", func(sCtx provider.StepCtx) {
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			user, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
 
-			time.Sleep(utils.BatchTransactionTimeout)
-			sCtx.WithNewStep("Get allowed balances if channels `fiat` and `cc`", func(sCtx provider.StepCtx) {
-				sCtx.WithNewAsyncStep("Get allowed balance in `fiat` channel", func(sCtx provider.StepCtx) {
-					resp, err := utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-						os.Getenv(utils.EnvHlfProxyAuthToken), "fiat", "balanceOf", userAddress)
-					sCtx.Assert().NoError(err)
-					sCtx.Assert().NotNil(resp)
-					sCtx.Assert().Equal("\"1\"", string(resp.Payload))
-				})
-				sCtx.WithNewAsyncStep("Get allowed balance in `cc` channel", func(sCtx provider.StepCtx) {
-					resp, err := utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-						os.Getenv(utils.EnvHlfProxyAuthToken), "cc", "allowedBalanceOf", userAddress, "fiat")
-					sCtx.Assert().NoError(err)
-					sCtx.Assert().NotNil(resp)
-					sCtx.Assert().Equal("\"0\"", string(resp.Payload))
-				})
-			})
+			signedArgs, err := issuer.Sign("fiat", "fiat", "emit", user.Address, "1")
+			sCtx.Require().NoError(err)
+			_, err = client.Invoke(ctx, "fiat", "emit", signedArgs...)
+			sCtx.Require().NoError(err)
+
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, "fiat", "balanceOf", user.Address)
+			}, utils.AmountEquals(amount.New(1)))
+			sCtx.Require().NoError(err)
+		})
+
+		t.WithNewStep("Swap amount greater than balance isn't visible and can't be cancelled", func(sCtx provider.StepCtx) {
+			swapper := swap.NewSwapper(client, swap.WithStateTimeout(swap.StateVisible, time.Second), swap.WithoutCancel())
+			result, err := swapper.Transfer(ctx, user, FiatName, "fiat", "cc", amount.New(2))
+			sCtx.Assert().Error(err)
+			sCtx.Require().NotNil(result)
+			sCtx.Require().Equal(swap.StateVisible, result.State)
+			sCtx.Assert().Nil(result.From)
+			sCtx.Assert().Nil(result.To)
+
+			signedArgs, err := user.Sign("fiat", "fiat", swap.CancelFn, result.ID)
+			sCtx.Require().NoError(err)
+			resp, err := client.Invoke(ctx, "fiat", swap.CancelFn, signedArgs...)
+			if err == nil {
+				utils.RequireTxFailedWith(ctx, sCtx, client, "fiat", resp.TransactionID, http.StatusInternalServerError, utils.ErrSwapNotFound)
+			} else {
+				sCtx.Assert().True(errors.Is(err, utils.ErrSwapNotFound))
+			}
+			requireBalance(ctx, sCtx, "fiat", user.Address, amount.New(1))
+		})
+
+		t.WithNewStep("Pending swap is cancelled by owner", func(sCtx provider.StepCtx) {
+			key, err := swap.NewKey()
+			sCtx.Require().NoError(err)
+			signedArgs, err := user.Sign("fiat", "fiat", swap.BeginFn, FiatName, "CC", "1", swap.Hash(key))
+			sCtx.Require().NoError(err)
+			resp, err := client.Invoke(ctx, "fiat", swap.BeginFn, signedArgs...)
+			sCtx.Require().NoError(err)
+			swapID := resp.TransactionID
+
+			for _, channel := range []string{"fiat", "cc"} {
+				_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+					_, err := swap.Get(ctx, client, channel, swapID)
+					return &utils.Response{}, err
+				}, nil)
+				sCtx.Require().NoError(err, "swap %s in %s", swapID, channel)
+			}
+			requireBalance(ctx, sCtx, "fiat", user.Address, amount.New(0))
+
+			signedArgs, err = user.Sign("fiat", "fiat", swap.CancelFn, swapID)
+			sCtx.Require().NoError(err)
+			resp, err = client.Invoke(ctx, "fiat", swap.CancelFn, signedArgs...)
+			sCtx.Require().NoError(err)
+			utils.RequireTxSucceeded(ctx, sCtx, client, "fiat", resp.TransactionID)
+
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				_, err := swap.Get(ctx, client, "fiat", swapID)
+				if errors.Is(err, utils.ErrSwapNotFound) {
+					return &utils.Response{}, nil
+				}
+				return nil, fmt.Errorf("swap %s isn't cancelled: %v", swapID, err)
+			}, nil)
+			sCtx.Assert().NoError(err)
+		})
+
+		t.WithNewStep("Check amount is returned to balance in `fiat` channel", func(sCtx provider.StepCtx) {
+			_, err := utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, "fiat", "balanceOf", user.Address)
+			}, utils.AmountEquals(amount.New(1)))
+			sCtx.Assert().NoError(err)
+		})
+	})
+}
+
+// TestSwapKey - complete swap with wrong key and with already used key, check amount is credited only once
+func TestSwapKey(t *testing.T) {
+	runner.Run(t, "swap token from fiat to cc with wrong and already used swap key", func(t provider.T) {
+		ctx := context.Background()
+		t.Severity(allure.CRITICAL)
+		t.Description("swapDone with wrong key is rejected and swap can't be completed twice")
+		t.Tags("negative", "swap")
+		requireBatchEvents(t)
+
+		var (
+			issuer, user *fixtures.Identity
+			key          string
+			swapID       string
+			err          error
+		)
+
+		t.WithNewStep("Register users and emit FIAT token to user", func(sCtx provider.StepCtx) {
+			issuer, err = fixtures.NewIssuer(ctx, client)
+			sCtx.Require().NoError(err)
+			user, err = fixtures.NewUser(ctx, client)
+			sCtx.Require().NoError(err)
+
+			signedArgs, err := issuer.Sign("fiat", "fiat", "emit", user.Address, "1")
+			sCtx.Require().NoError(err)
+			_, err = client.Invoke(ctx, "fiat", "emit", signedArgs...)
+			sCtx.Require().NoError(err)
+
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, "fiat", "balanceOf", user.Address)
+			}, utils.AmountEquals(amount.New(1)))
+			sCtx.Require().NoError(err)
+		})
+
+		t.WithNewStep("Begin swap with random key", func(sCtx provider.StepCtx) {
+			key, err = swap.NewKey()
+			sCtx.Require().NoError(err)
+
+			signedArgs, err := user.Sign("fiat", "fiat", swap.BeginFn, FiatName, "CC", "1", swap.Hash(key))
+			sCtx.Require().NoError(err)
+			resp, err := client.Invoke(ctx, "fiat", swap.BeginFn, signedArgs...)
+			sCtx.Require().NoError(err)
+			swapID = resp.TransactionID
+		})
+
+		t.WithNewStep("Complete swap with wrong key", func(sCtx provider.StepCtx) {
+			var ccSwap *pb.Swap
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				var err error
+				ccSwap, err = swap.Get(ctx, client, "cc", swapID)
+				return &utils.Response{}, err
+			}, nil)
+			sCtx.Require().NoError(err)
+
+			wrongKey, err := swap.NewKey()
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(errors.Is(swap.VerifyKey(ccSwap, wrongKey), utils.ErrIncorrectSwapKey))
+			sCtx.Assert().NoError(swap.VerifyKey(ccSwap, key))
+
+			resp, err := client.Invoke(ctx, "cc", swap.DoneFn, swapID, wrongKey)
+			if err == nil {
+				utils.RequireTxFailedWith(ctx, sCtx, client, "cc", resp.TransactionID, http.StatusInternalServerError, utils.ErrIncorrectSwapKey)
+			} else {
+				sCtx.Assert().True(errors.Is(err, utils.ErrIncorrectSwapKey))
+			}
+
+			_, err = swap.Get(ctx, client, "cc", swapID)
+			sCtx.Assert().NoError(err)
+
+			balance, err := client.AllowedBalanceOf(ctx, "cc", user.Address, FiatName)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(amount.IsZero(balance))
+		})
+
+		t.WithNewStep("Complete swap with correct key", func(sCtx provider.StepCtx) {
+			_, err = client.Invoke(ctx, "cc", swap.DoneFn, swapID, key)
+			sCtx.Require().NoError(err)
+
+			_, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+				return client.Query(ctx, "cc", "allowedBalanceOf", user.Address, FiatName)
+			}, utils.AmountEquals(amount.New(1)))
+			sCtx.Assert().NoError(err)
+		})
+
+		t.WithNewStep("Complete swap again with already used key", func(sCtx provider.StepCtx) {
+			resp, err := client.Invoke(ctx, "cc", swap.DoneFn, swapID, key)
+			if err == nil {
+				utils.RequireTxFailedWith(ctx, sCtx, client, "cc", resp.TransactionID, http.StatusInternalServerError, utils.ErrSwapNotFound)
+			} else {
+				sCtx.Assert().True(errors.Is(err, utils.ErrSwapNotFound))
+			}
+
+			balance, err := client.AllowedBalanceOf(ctx, "cc", user.Address, FiatName)
+			sCtx.Require().NoError(err)
+			sCtx.Assert().True(amount.Equal(amount.New(1), balance))
 		})
 	})
 }
@@ -2,16 +2,14 @@ package integration
 
 import (
 	"context"
-	"os"
 	"testing"
-	"time"
 
-	"github.com/btcsuite/btcutil/base58"
 	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/ozontech/allure-go/pkg/framework/runner"
+	"github.com/tickets-dao/integration/amount"
+	"github.com/tickets-dao/integration/fixtures"
 	"github.com/tickets-dao/integration/utils"
-	"golang.org/x/crypto/ed25519"
 )
 
 // TestTxTTLSuccess - create user 'from', emit amount to user 'userFrom'
@@ -24,49 +22,20 @@ func TestTxTTLSuccess(t *testing.T) {
 		var (
 			ctx = context.Background()
 
-			issuerPrivateKey         ed25519.PrivateKey
-			issuerPubKey, userPubKey ed25519.PublicKey
+			issuer, user *fixtures.Identity
 		)
 
-		t.WithNewStep("Generate cryptos for users and saving it to `acl` chaincode", func(sCtx provider.StepCtx) {
-			sCtx.WithNewAsyncStep("Get crypto for issuer from env and saving to `acl` chaincode", func(sCtx provider.StepCtx) {
+		t.WithNewStep("Register users in `acl` chaincode", func(sCtx provider.StepCtx) {
+			sCtx.WithNewAsyncStep("Register issuer with crypto from env", func(sCtx provider.StepCtx) {
 				var err error
-				issuerPrivateKey, issuerPubKey, err = utils.GetPrivateKeyFromBase58Check(os.Getenv(utils.EnvFiatIssuerPrivateKey))
-				sCtx.Assert().NoError(err)
-				issuerPKeyStr := base58.Encode(issuerPubKey)
-
-				sCtx.WithNewStep("Add issuer user to `acl` chaincode", func(sCtx provider.StepCtx) {
-					_, _ = utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-						os.Getenv(utils.EnvHlfProxyAuthToken),
-						"acl", "addUser", issuerPKeyStr, "test", "testuser", "true")
-				})
-				sCtx.WithNewStep("Check issuer user in `acl` chaincode", func(sCtx provider.StepCtx) {
-					_, err = utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-						os.Getenv(utils.EnvHlfProxyAuthToken),
-						"acl", "checkKeys", issuerPKeyStr)
-					sCtx.Assert().NoError(err)
-				})
+				issuer, err = fixtures.NewIssuer(ctx, client)
+				sCtx.Require().NoError(err)
 			})
 
-			sCtx.WithNewAsyncStep("Generate cryptos for user and save to `acl` chaincode", func(sCtx provider.StepCtx) {
+			sCtx.WithNewAsyncStep("Register user", func(sCtx provider.StepCtx) {
 				var err error
-				_, userPubKey, err = utils.GeneratePrivateAndPublicKey()
-				sCtx.Assert().NoError(err)
-				userPubKeyStr := base58.Encode(userPubKey)
-
-				sCtx.WithNewStep("Add user to `acl` chaincode", func(sCtx provider.StepCtx) {
-					_, err = utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-						os.Getenv(utils.EnvHlfProxyAuthToken),
-						"acl", "addUser", userPubKeyStr, "test", "testuser", "true")
-					sCtx.Assert().NoError(err)
-				})
-
-				sCtx.WithNewStep("Check user in `acl` chaincode", func(sCtx provider.StepCtx) {
-					_, err = utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-						os.Getenv(utils.EnvHlfProxyAuthToken),
-						"acl", "checkKeys", userPubKeyStr)
-					sCtx.Assert().NoError(err)
-				})
+				user, err = fixtures.NewUser(ctx, client)
+				sCtx.Require().NoError(err)
 			})
 		})
 
@@ -78,36 +47,28 @@ func TestTxTTLSuccess(t *testing.T) {
 				resp           *utils.Response
 			)
 
-			userAddress, err := utils.GetAddressByPublicKey(userPubKey)
-			sCtx.Assert().NoError(err)
-
 			sCtx.WithNewStep("Sign arguments before emission process", func(sCtx provider.StepCtx) {
-				signedEmitArgs, err = utils.Sign(
-					issuerPrivateKey,
-					issuerPubKey,
+				signedEmitArgs, err = issuer.Sign(
 					"fiat",
 					"fiat",
 					"emit",
-					[]string{userAddress, emitAmount},
+					user.Address,
+					emitAmount,
 				)
 				sCtx.Assert().NoError(err)
 			})
 
 			sCtx.WithNewStep("Invoke fiat chaincode by issuer for token emission", func(sCtx provider.StepCtx) {
-				_, err = utils.Invoke(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken),
-					"fiat", "emit", signedEmitArgs...)
+				_, err = client.Invoke(ctx, "fiat", "emit", signedEmitArgs...)
 				sCtx.Assert().NoError(err)
 			})
 
-			time.Sleep(utils.BatchTransactionTimeout)
 			sCtx.WithNewStep("Check balance of user after emission", func(sCtx provider.StepCtx) {
-				resp, err = utils.Query(ctx, os.Getenv(utils.EnvHlfProxyURL),
-					os.Getenv(utils.EnvHlfProxyAuthToken),
-					"fiat", "balanceOf", userAddress)
+				resp, err = utils.Eventually(ctx, func(ctx context.Context) (*utils.Response, error) {
+					return client.Query(ctx, "fiat", "balanceOf", user.Address)
+				}, utils.AmountEquals(amount.New(1)))
 				sCtx.Assert().NoError(err)
 				sCtx.Assert().NotNil(resp)
-				sCtx.Assert().Equal("\"1\"", string(resp.Payload))
 			})
 		})
 	})
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+// AddressLength - length of address in bytes, sha3-256 of public key or of joined public keys of multisig
+const AddressLength = 32
+
+var (
+	// ErrInvalidAddress - string isn't address in base58 check or bytes have wrong length
+	ErrInvalidAddress = errors.New("invalid address")
+	// ErrInvalidPublicKey - string isn't ed25519 public key in base58
+	ErrInvalidPublicKey = errors.New("invalid public key")
+)
+
+// Address - address of user or multisig in foundation library. Bytes are stored in pb.Address.Address,
+// pb.Swap.Owner and accounting records, string is base58 check with the first byte as version
+type Address [AddressLength]byte
+
+// ParseAddress - address from base58 check string, inverse of Address.String
+func ParseAddress(address string) (Address, error) {
+	decoded, ver, err := base58.CheckDecode(address)
+	if err != nil {
+		return Address{}, fmt.Errorf("%w %q: %v", ErrInvalidAddress, address, err)
+	}
+	return AddressFromBytes(append([]byte{ver}, decoded...))
+}
+
+// AddressFromBytes - address from bytes of protobuf messages
+func AddressFromBytes(address []byte) (Address, error) {
+	var a Address
+	if len(address) != AddressLength {
+		return a, fmt.Errorf("%w: length %d, expected %d", ErrInvalidAddress, len(address), AddressLength)
+	}
+	copy(a[:], address)
+	return a, nil
+}
+
+// AddressOfPublicKey - address of user with public key, see GetAddressByPublicKey
+func AddressOfPublicKey(publicKey ed25519.PublicKey) Address {
+	return sha3.Sum256(publicKey)
+}
+
+// Bytes - address as stored in protobuf messages
+func (a Address) Bytes() []byte {
+	return append([]byte(nil), a[:]...)
+}
+
+// String - address in base58 check, used by token chaincodes
+func (a Address) String() string {
+	return base58.CheckEncode(a[1:], a[0])
+}
+
+// IsZero - address isn't set
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// EqualBytes - address is equal to bytes of protobuf message
+func (a Address) EqualBytes(address []byte) bool {
+	return bytes.Equal(a[:], address)
+}
+
+// AddressEqual - base58 check address is equal to bytes of protobuf message, invalid address is never equal
+func AddressEqual(address string, raw []byte) bool {
+	a, err := ParseAddress(address)
+	return err == nil && a.EqualBytes(raw)
+}
+
+// EncodeAddress - bytes of protobuf message in base58 check, empty if bytes are empty
+func EncodeAddress(address []byte) (string, error) {
+	if len(address) == 0 {
+		return "", nil
+	}
+	a, err := AddressFromBytes(address)
+	if err != nil {
+		return "", err
+	}
+	return a.String(), nil
+}
+
+// ValidateAddress - address is well-formed base58 check of AddressLength bytes
+func ValidateAddress(address string) error {
+	_, err := ParseAddress(address)
+	return err
+}
+
+// ValidatePublicKeyBase58 - public key in base58 as used by acl chaincode, multisig keys joined by '/' are not accepted
+func ValidatePublicKeyBase58(publicKey string) (ed25519.PublicKey, error) {
+	decoded := base58.Decode(publicKey)
+	if len(decoded) != ed25519.PublicKeySize || base58.Encode(decoded) != publicKey {
+		return nil, fmt.Errorf("%w %q", ErrInvalidPublicKey, publicKey)
+	}
+	return decoded, nil
+}
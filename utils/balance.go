@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/tickets-dao/integration/amount"
+)
+
+const (
+	// BalanceOfFn - query of token balance of address
+	BalanceOfFn = "balanceOf"
+	// AllowedBalanceOfFn - query of balance of foreign token received by swap
+	AllowedBalanceOfFn = "allowedBalanceOf"
+	// IndustrialBalanceOfFn - query of balances of industrial token by groups
+	IndustrialBalanceOfFn = "industrialBalanceOf"
+)
+
+// BalanceOf - token balance of address in chaincode cc
+func (c *Client) BalanceOf(ctx context.Context, cc, address string) (*big.Int, error) {
+	return c.queryAmount(ctx, cc, BalanceOfFn, address)
+}
+
+// AllowedBalanceOf - balance of foreign token in chaincode cc, example token 'fiat' in chaincode 'cc'
+func (c *Client) AllowedBalanceOf(ctx context.Context, cc, address, token string) (*big.Int, error) {
+	return c.queryAmount(ctx, cc, AllowedBalanceOfFn, address, token)
+}
+
+// IndustrialBalanceOf - balances of industrial token by groups, groups with zero balance are absent
+func (c *Client) IndustrialBalanceOf(ctx context.Context, cc, address string) (map[string]*big.Int, error) {
+	resp, err := c.Query(ctx, cc, IndustrialBalanceOfFn, address)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := amount.FromPayloadMap(resp.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", cc, IndustrialBalanceOfFn, err)
+	}
+	return balances, nil
+}
+
+func (c *Client) queryAmount(ctx context.Context, cc, fcn string, args ...string) (*big.Int, error) {
+	resp, err := c.Query(ctx, cc, fcn, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := amount.FromPayload(resp.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", cc, fcn, err)
+	}
+	return v, nil
+}
+
+// AmountEquals - predicate is satisfied when response payload is amount equal to expected, see BalanceOf
+func AmountEquals(expected *big.Int) Predicate {
+	return func(resp *Response) bool {
+		if resp == nil {
+			return false
+		}
+		v, err := amount.FromPayload(resp.Payload)
+		return err == nil && amount.Equal(v, expected)
+	}
+}
+
+// IndustrialIssued - predicate is satisfied when response payload has balance of any group, see IndustrialBalanceOf.
+// Issuer of industrial token receives every group by initialize
+func IndustrialIssued() Predicate {
+	return func(resp *Response) bool {
+		if resp == nil {
+			return false
+		}
+		balances, err := amount.FromPayloadMap(resp.Payload)
+		return err == nil && len(balances) != 0
+	}
+}
+
+// IndustrialAmountEquals - predicate is satisfied when amount of group in response payload is equal to expected,
+// see IndustrialBalanceOf
+func IndustrialAmountEquals(group string, expected *big.Int) Predicate {
+	return func(resp *Response) bool {
+		if resp == nil {
+			return false
+		}
+		balances, err := amount.FromPayloadMap(resp.Payload)
+		return err == nil && amount.Equal(balances[group], expected)
+	}
+}
@@ -4,35 +4,24 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/btcsuite/btcutil/base58"
 	"golang.org/x/crypto/ed25519"
-	"golang.org/x/crypto/sha3"
 )
 
-// signMessage - sign arguments with private key in ed25519
-func signMessage(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey, result []string) ([]byte, error) {
-	message := sha3.Sum256([]byte(strings.Join(result, "")))
-	sig := ed25519.Sign(privateKey, message[:])
-	if !ed25519.Verify(publicKey, message[:], sig) {
-		err := fmt.Errorf("valid signature rejected")
-		return nil, err
-	}
-	return sig, nil
-}
-
-// Sign - sign arguments before send to hlf. create message with certain order arguments expected by chaincode validation in foundation library
+// Sign - sign arguments before send to hlf. create message with certain order arguments expected by chaincode validation in foundation library.
+// Nonce is taken from DefaultNonceSource, see SignedTx for layout of result
 func Sign(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey, channel string, chaincode string, methodName string, args []string) ([]string, error) {
-	nonce := GetNonce()
-	result := append(append([]string{methodName, "", chaincode, channel}, args...), nonce, ConvertPublicKeyToBase58(publicKey))
+	return SignWithNonce(privateKey, publicKey, channel, chaincode, methodName, args, DefaultNonceSource.Next(publicKey))
+}
 
-	sMsg, err := signMessage(privateKey, publicKey, result)
+// SignWithNonce - sign arguments like Sign with explicit nonce, allows to craft past, future or duplicate nonces
+func SignWithNonce(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey, channel string, chaincode string, methodName string, args []string, nonce string) ([]string, error) {
+	tx, err := SignTx(&KeySigner{privateKey: privateKey, publicKey: publicKey}, channel, chaincode, methodName, nonce, args...)
 	if err != nil {
-		return nil, fmt.Errorf("sign message: %w", err)
+		return nil, err
 	}
-
-	return append(result[1:], base58.Encode(sMsg)), nil
+	return tx.Args(), nil
 }
 
 // GeneratePrivateAndPublicKey - create new private and public key
@@ -47,8 +36,7 @@ func GetAddressByPublicKey(publicKey ed25519.PublicKey) (string, error) {
 		return "", errors.New("publicKey can't be empty")
 	}
 
-	hash := sha3.Sum256(publicKey)
-	return base58.CheckEncode(hash[1:], hash[0]), nil
+	return AddressOfPublicKey(publicKey).String(), nil
 }
 
 // GetPrivateKeyFromBase58Check - get private key type Ed25519 by string - Base58Check encoded private key
@@ -69,3 +57,8 @@ func GetPrivateKeyFromBase58Check(secretKey string) (ed25519.PrivateKey, ed25519
 func ConvertPublicKeyToBase58(publicKey ed25519.PublicKey) string {
 	return base58.Encode(publicKey)
 }
+
+// ConvertPrivateKeyToBase58Check - encode private key type Ed25519 to Base58Check, inverse of GetPrivateKeyFromBase58Check
+func ConvertPrivateKeyToBase58Check(privateKey ed25519.PrivateKey) string {
+	return base58.CheckEncode(privateKey[1:], privateKey[0])
+}
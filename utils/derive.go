@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// EnvKeySeed - master seed in hex for keys derived by KeyDeriver, random seed is used if it is empty
+	EnvKeySeed = "KEY_SEED"
+
+	// slip10Curve - key of hmac for master key of ed25519 curve in SLIP-0010
+	slip10Curve = "ed25519 seed"
+	// hardenedOffset - SLIP-0010 derives only hardened children for ed25519
+	hardenedOffset = 0x80000000
+
+	seedMinLen     = 16
+	seedMaxLen     = 64
+	seedDefaultLen = 32
+)
+
+// KeyDeriver - deterministic ed25519 keys derived from master seed by path in SLIP-0010 style.
+// Keys, public keys and addresses are the same for the same seed and path, so run can be repeated with the same accounts
+type KeyDeriver struct {
+	seed []byte
+}
+
+// NewKeyDeriver - deriver with master seed of 16..64 bytes
+func NewKeyDeriver(seed []byte) (*KeyDeriver, error) {
+	if len(seed) < seedMinLen || len(seed) > seedMaxLen {
+		return nil, fmt.Errorf("incorrect seed length %d, expected %d..%d bytes", len(seed), seedMinLen, seedMaxLen)
+	}
+	return &KeyDeriver{seed: append([]byte(nil), seed...)}, nil
+}
+
+// KeyDeriverFromEnv - deriver with seed in hex from EnvKeySeed, seed is random if it isn't set
+func KeyDeriverFromEnv() (*KeyDeriver, error) {
+	env := os.Getenv(EnvKeySeed)
+	if env == "" {
+		seed := make([]byte, seedDefaultLen)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, fmt.Errorf("random seed: %w", err)
+		}
+		return NewKeyDeriver(seed)
+	}
+
+	seed, err := hex.DecodeString(env)
+	if err != nil {
+		return nil, fmt.Errorf("seed from %s: %w", EnvKeySeed, err)
+	}
+	return NewKeyDeriver(seed)
+}
+
+// Seed - master seed in hex, value of EnvKeySeed to repeat run with the same keys
+func (d *KeyDeriver) Seed() string {
+	return hex.EncodeToString(d.seed)
+}
+
+// Derive - key of path like 'test/transfer/userFrom'. Every segment of path is hardened child,
+// index of child is the first 31 bits of sha256 of segment
+func (d *KeyDeriver) Derive(path string) (ed25519.PrivateKey, ed25519.PublicKey, error) {
+	if path == "" {
+		return nil, nil, errors.New("empty path")
+	}
+
+	key, chainCode := hmacSHA512([]byte(slip10Curve), d.seed)
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			return nil, nil, fmt.Errorf("empty segment of path %s", path)
+		}
+		data := make([]byte, 0, 1+len(key)+4) //nolint:gomnd
+		data = append(data, 0)
+		data = append(data, key...)
+		data = append(data, make([]byte, 4)...) //nolint:gomnd
+		binary.BigEndian.PutUint32(data[len(data)-4:], segmentIndex(segment))
+		key, chainCode = hmacSHA512(chainCode, data)
+	}
+
+	privateKey := ed25519.NewKeyFromSeed(key)
+	publicKey, ok := privateKey.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, nil, errors.New("type assertion failed")
+	}
+	return privateKey, publicKey, nil
+}
+
+// segmentIndex - hardened index of path segment
+func segmentIndex(segment string) uint32 {
+	sum := sha256.Sum256([]byte(segment))
+	return binary.BigEndian.Uint32(sum[:4])&(hardenedOffset-1) | hardenedOffset
+}
+
+// hmacSHA512 - left and right halves of hmac-sha512, key and chain code in SLIP-0010
+func hmacSHA512(key, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:ed25519.SeedSize], sum[ed25519.SeedSize:]
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Known failures of foundation library, compare with errors.Is
+var (
+	// ErrIncorrectNonce - nonce is already used or is out of nonce ttl window
+	ErrIncorrectNonce = errors.New("incorrect nonce")
+	// ErrUserAlreadyExists - public key is already registered in acl
+	ErrUserAlreadyExists = errors.New("user already exists")
+	// ErrInsufficientFunds - balance is less than requested amount
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrSwapNotFound - swap with requested id doesn't exist
+	ErrSwapNotFound = errors.New("swap not found")
+	// ErrAccessDenied - caller has no rights for requested operation
+	ErrAccessDenied = errors.New("access denied")
+	// ErrIncorrectSwapKey - hash of swap key doesn't match hash of swap
+	ErrIncorrectSwapKey = errors.New("incorrect swap key")
+	// ErrIncorrectSignature - signature doesn't match signed arguments or public key
+	ErrIncorrectSignature = errors.New("incorrect signature")
+	// ErrAmountOutOfLimits - amount of buyToken or buyBack is out of min and max of rate
+	ErrAmountOutOfLimits = errors.New("amount out of limits")
+	// ErrGroupNotMatured - group of industrial token can't be redeemed before maturity
+	ErrGroupNotMatured = errors.New("group is not matured")
+	// ErrBlacklisted - blacklisted address can't send or receive token
+	ErrBlacklisted = errors.New("address is blacklisted")
+	// ErrGraylisted - graylisted address can't sign transactions
+	ErrGraylisted = errors.New("address is graylisted")
+	// ErrPublicKeyNotFound - public key isn't registered in acl or it is replaced by changePublicKey
+	ErrPublicKeyNotFound = errors.New("public key not found")
+)
+
+// knownErrors - patterns of chaincode error messages for every known failure, case is ignored.
+// Patterns follow wording of foundation library and acl chaincode, fake hlf proxy service uses the same wording.
+// Pattern of ErrUserAlreadyExists is anchored to addUser and addMultisig of acl, other "already exists" failures
+// like existing swap don't match it
+var knownErrors = map[error]*regexp.Regexp{
+	ErrIncorrectNonce:     regexp.MustCompile(`(?i)incorrect nonce`),
+	ErrUserAlreadyExists:  regexp.MustCompile(`(?i)(user|multisig(nature)?)( associated)? with (the )?public keys? \S+ (is )?already exists`),
+	ErrInsufficientFunds:  regexp.MustCompile(`(?i)insufficient (funds|balance)`),
+	ErrSwapNotFound:       regexp.MustCompile(`(?i)swap (doesn't|does not) exist|swap not found`),
+	ErrAccessDenied:       regexp.MustCompile(`(?i)unauthorized|access denied|permission denied`),
+	ErrIncorrectSwapKey:   regexp.MustCompile(`(?i)incorrect (swap )?key`),
+	ErrIncorrectSignature: regexp.MustCompile(`(?i)incorrect signature|signature is incorrect|invalid signature`),
+	ErrAmountOutOfLimits:  regexp.MustCompile(`(?i)amount out of limits`),
+	ErrGroupNotMatured:    regexp.MustCompile(`(?i)is not matured`),
+	ErrBlacklisted:        regexp.MustCompile(`(?i)blacklisted|black ?list`),
+	ErrGraylisted:         regexp.MustCompile(`(?i)graylisted|gray ?list`),
+	ErrPublicKeyNotFound:  regexp.MustCompile(`(?i)no public key|public key \S+ not found|public key not found`),
+}
+
+// ProxyError - failed response of hlf proxy service
+type ProxyError struct {
+	// StatusCode - http status of response
+	StatusCode int
+	// Code - error code from response body
+	Code int64
+	// Message - error message from response body, raw body if it is not ResponseError
+	Message string
+	// Body - raw response body
+	Body []byte
+	// Chaincode - called chaincode
+	Chaincode string
+	// Fcn - called chaincode function
+	Fcn string
+}
+
+func newProxyError(statusCode int, body []byte, cc, fcn string) *ProxyError {
+	proxyErr := &ProxyError{
+		StatusCode: statusCode,
+		Body:       body,
+		Chaincode:  cc,
+		Fcn:        fcn,
+	}
+
+	responseError := &ResponseError{}
+	if err := json.Unmarshal(body, responseError); err == nil && responseError.Message != "" {
+		proxyErr.Code = responseError.Code
+		proxyErr.Message = responseError.Message
+	} else {
+		proxyErr.Message = string(body)
+	}
+
+	return proxyErr
+}
+
+func (e *ProxyError) Error() string {
+	return fmt.Sprintf("%s %s: status %d, code %d: %s", e.Chaincode, e.Fcn, e.StatusCode, e.Code, e.Message)
+}
+
+// Is - report whether message of response matches one of known failures
+func (e *ProxyError) Is(target error) bool {
+	pattern, ok := knownErrors[target]
+	return ok && pattern.MatchString(e.Message)
+}
+
+// TxError - failure of transaction executed in batch, invoke of transaction itself succeeds
+type TxError struct {
+	// TxID - transaction id in hex
+	TxID string
+	// Method - method of transaction
+	Method string
+	// Code - error code of transaction
+	Code int32
+	// Message - error message of transaction
+	Message string
+}
+
+func (e *TxError) Error() string {
+	return fmt.Sprintf("transaction %s %s: code %d: %s", e.TxID, e.Method, e.Code, e.Message)
+}
+
+// Is - report whether message of transaction error matches one of known failures
+func (e *TxError) Is(target error) bool {
+	pattern, ok := knownErrors[target]
+	return ok && pattern.MatchString(e.Message)
+}
@@ -4,9 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	invokeRequestType = "invoke"
+	queryRequestType  = "query"
 )
 
 type Request struct {
@@ -28,49 +38,192 @@ type ResponseError struct {
 	Message string `json:"message"`
 }
 
-// Invoke ...
-func Invoke(ctx context.Context, url, token, cc, fcn string, args ...string) (*Response, error) {
-	newCtx, cancel := context.WithTimeout(ctx, InvokeTimeout)
+// Call - finished request to hlf proxy service passed to hooks
+type Call struct {
+	// RequestType - 'invoke' or 'query'
+	RequestType string
+	Request     *Request
+	// Response - nil if request failed
+	Response *Response
+	Err      error
+	Duration time.Duration
+}
+
+// Hook - called after every request to hlf proxy service
+type Hook func(ctx context.Context, call *Call)
+
+// Client - client of hlf proxy service
+type Client struct {
+	// url - domain and port for hlf proxy service, example http://localhost:9001
+	url string
+	// authToken - support Basic Auth with auth token
+	authToken     string
+	httpClient    *http.Client
+	invokeTimeout time.Duration
+	queryTimeout  time.Duration
+	userAgent     string
+	hooks         []Hook
+	// batchEvents - chaincodes answer BatchTxEventFn, see WithBatchEvents
+	batchEvents bool
+}
+
+// ClientOption - configures Client
+type ClientOption func(c *Client)
+
+// WithAuthToken - token for Basic Auth
+func WithAuthToken(token string) ClientOption {
+	return func(c *Client) {
+		c.authToken = token
+	}
+}
+
+// WithHTTPClient - use custom http client instead of http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithInvokeTimeout - override InvokeTimeout
+func WithInvokeTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.invokeTimeout = timeout
+	}
+}
+
+// WithQueryTimeout - override QueryTimeout
+func WithQueryTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.queryTimeout = timeout
+	}
+}
+
+// WithUserAgent - user agent header of every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHook - add hook called after every request
+func WithHook(hook Hook) ClientOption {
+	return func(c *Client) {
+		c.hooks = append(c.hooks, hook)
+	}
+}
+
+// WithBatchEvents - chaincodes answer BatchTxEventFn with batch event of transaction, like fake hlf proxy service.
+// Foundation chaincodes have no such query, so without this option transactions are awaited by state
+func WithBatchEvents() ClientOption {
+	return func(c *Client) {
+		c.batchEvents = true
+	}
+}
+
+// BatchEvents - batch events of transactions can be queried, see WithBatchEvents
+func (c *Client) BatchEvents() bool {
+	return c.batchEvents
+}
+
+// NewClient - create client of hlf proxy service, baseURL must be absolute http or https url
+func NewClient(baseURL string, opts ...ClientOption) (*Client, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("url %q: scheme must be http or https", baseURL)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("url %q: host is empty", baseURL)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return nil, fmt.Errorf("url %q: query and fragment are not allowed", baseURL)
+	}
+
+	c := &Client{
+		url:           strings.TrimRight(baseURL, "/"),
+		httpClient:    http.DefaultClient,
+		invokeTimeout: InvokeTimeout,
+		queryTimeout:  QueryTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// NewClientFromEnv - create client of hlf proxy service with url from EnvHlfProxyURL and token from EnvHlfProxyAuthToken
+func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
+	return NewClient(os.Getenv(EnvHlfProxyURL), append([]ClientOption{WithAuthToken(os.Getenv(EnvHlfProxyAuthToken))}, opts...)...)
+}
+
+// Invoke - send invoke request to hlf through hlf proxy service
+func (c *Client) Invoke(ctx context.Context, cc, fcn string, args ...string) (*Response, error) {
+	newCtx, cancel := context.WithTimeout(ctx, c.invokeTimeout)
 	defer cancel()
-	return doRequest(newCtx, url, token, "invoke", cc, fcn, args...)
+	return c.doRequest(newCtx, invokeRequestType, cc, fcn, args...)
 }
 
-// Query ...
-func Query(ctx context.Context, url, token, cc, fcn string, args ...string) (*Response, error) {
-	newCtx, cancel := context.WithTimeout(ctx, QueryTimeout)
+// Query - send query request to hlf through hlf proxy service
+func (c *Client) Query(ctx context.Context, cc, fcn string, args ...string) (*Response, error) {
+	newCtx, cancel := context.WithTimeout(ctx, c.queryTimeout)
 	defer cancel()
-	return doRequest(newCtx, url, token, "query", cc, fcn, args...)
+	return c.doRequest(newCtx, queryRequestType, cc, fcn, args...)
 }
 
-func doRequest(ctx context.Context, url string, token, reqType, cc, fcn string, args ...string) (*Response, error) {
-	requestData := Request{
+func (c *Client) doRequest(ctx context.Context, reqType, cc, fcn string, args ...string) (*Response, error) {
+	requestData := &Request{
 		Args:        AsBytes(args...),
 		ChaincodeID: cc,
 		Fcn:         fcn,
 	}
 
+	start := time.Now()
+	resp, err := c.send(ctx, reqType, requestData)
+	for _, hook := range c.hooks {
+		hook(ctx, &Call{
+			RequestType: reqType,
+			Request:     requestData,
+			Response:    resp,
+			Err:         err,
+			Duration:    time.Since(start),
+		})
+	}
+
+	return resp, err
+}
+
+func (c *Client) send(ctx context.Context, reqType string, requestData *Request) (*Response, error) {
 	reqBody, err := json.Marshal(requestData)
 	if err != nil {
-		return nil, fmt.Errorf("json unmarshal: %w", err)
+		return nil, fmt.Errorf("json marshal: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/%s", url, reqType), bytes.NewReader(reqBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/%s", c.url, reqType), bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("http new request: %w", err)
 	}
 
-	req.Header.Add("authorization", fmt.Sprintf("Basic %s", token))
+	req.Header.Add("authorization", fmt.Sprintf("Basic %s", c.authToken))
 	req.Header.Add("content-type", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("user-agent", c.userAgent)
+	}
 
-	httpResponse, err := http.DefaultClient.Do(req)
+	httpResponse, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("http client do: %w", err)
 	}
+	if httpResponse == nil {
+		return nil, errors.New("response not found")
+	}
 
 	defer func() {
 		clErr := httpResponse.Body.Close()
 		if clErr != nil {
-			fmt.Printf("body close error: %v\n", err)
+			fmt.Printf("body close error: %v\n", clErr)
 		}
 	}()
 	body, err := io.ReadAll(httpResponse.Body)
@@ -79,13 +232,7 @@ func doRequest(ctx context.Context, url string, token, reqType, cc, fcn string,
 	}
 
 	if httpResponse.StatusCode != http.StatusOK {
-		responseError := &ResponseError{}
-		err = json.Unmarshal(body, responseError)
-		if err != nil {
-			return nil, fmt.Errorf("json unmarshal: %w", err)
-		}
-
-		return nil, fmt.Errorf(responseError.Message)
+		return nil, newProxyError(httpResponse.StatusCode, body, requestData.ChaincodeID, requestData.Fcn)
 	}
 
 	var resp Response
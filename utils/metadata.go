@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/tickets-dao/integration/amount"
+	pb "github.com/tickets-dao/integration/proto"
+)
+
+// MetadataFn - query of token config: name, symbol, methods, fee, rates and groups of industrial token
+const MetadataFn = "metadata"
+
+// metadata - part of metadata of token chaincode with token config, amounts are json numbers or strings
+type metadata struct {
+	Symbol        string          `json:"symbol"`
+	TotalEmission json.RawMessage `json:"total_emission"`
+	Fee           struct {
+		Address  string          `json:"address"`
+		Currency string          `json:"currency"`
+		Fee      json.RawMessage `json:"fee"`
+		Floor    json.RawMessage `json:"floor"`
+		Cap      json.RawMessage `json:"cap"`
+	} `json:"fee"`
+	Rates []struct {
+		DealType string          `json:"deal_type"`
+		Currency string          `json:"currency"`
+		Rate     json.RawMessage `json:"rate"`
+		Min      json.RawMessage `json:"min"`
+		Max      json.RawMessage `json:"max"`
+	} `json:"rates"`
+	Groups []struct {
+		Name     string          `json:"name"`
+		Amount   json.RawMessage `json:"amount"`
+		Maturity time.Time       `json:"maturity"`
+		Note     string          `json:"note"`
+	} `json:"groups"`
+	Initialized bool `json:"initialized"`
+}
+
+// TokenConfig - symbol and config of token from metadata of chaincode cc, fee is nil if currency of fee is empty
+func (c *Client) TokenConfig(ctx context.Context, cc string) (string, *pb.Token, error) {
+	md, err := c.metadata(ctx, cc)
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := md.token()
+	if err != nil {
+		return "", nil, err
+	}
+	return md.Symbol, token, nil
+}
+
+// IndustrialConfig - symbol and config of industrial token from metadata of chaincode cc with groups in order of metadata
+func (c *Client) IndustrialConfig(ctx context.Context, cc string) (string, *pb.Industrial, error) {
+	md, err := c.metadata(ctx, cc)
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := md.token()
+	if err != nil {
+		return "", nil, err
+	}
+
+	industrial := &pb.Industrial{
+		Fee:         token.Fee,
+		Rates:       token.Rates,
+		FeeAddress:  token.FeeAddress,
+		Initialized: md.Initialized,
+	}
+	for _, g := range md.Groups {
+		group := &pb.IndustrialGroup{Id: g.Name, Maturity: g.Maturity.Unix(), Note: g.Note}
+		if group.Emission, err = rawAmount(g.Amount); err != nil {
+			return "", nil, fmt.Errorf("group %s: %w", g.Name, err)
+		}
+		industrial.Groups = append(industrial.Groups, group)
+	}
+	return md.Symbol, industrial, nil
+}
+
+func (c *Client) metadata(ctx context.Context, cc string) (*metadata, error) {
+	resp, err := c.Query(ctx, cc, MetadataFn)
+	if err != nil {
+		return nil, err
+	}
+
+	md := &metadata{}
+	if err = json.Unmarshal(resp.Payload, md); err != nil {
+		return nil, fmt.Errorf("json unmarshal metadata: %w", err)
+	}
+	return md, nil
+}
+
+// token - total emission, fee and rates of metadata
+func (md *metadata) token() (*pb.Token, error) {
+	var err error
+	token := &pb.Token{}
+	if token.TotalEmission, err = rawAmount(md.TotalEmission); err != nil {
+		return nil, fmt.Errorf("total emission: %w", err)
+	}
+	if md.Fee.Address != "" {
+		address, err := ParseAddress(md.Fee.Address)
+		if err != nil {
+			return nil, fmt.Errorf("fee address: %w", err)
+		}
+		token.FeeAddress = address.Bytes()
+	}
+	if md.Fee.Currency != "" {
+		token.Fee = &pb.TokenFee{Currency: md.Fee.Currency}
+		if err = rawAmounts(map[*[]byte]json.RawMessage{
+			&token.Fee.Fee:   md.Fee.Fee,
+			&token.Fee.Floor: md.Fee.Floor,
+			&token.Fee.Cap:   md.Fee.Cap,
+		}); err != nil {
+			return nil, fmt.Errorf("fee: %w", err)
+		}
+	}
+	for _, r := range md.Rates {
+		rate := &pb.TokenRate{DealType: r.DealType, Currency: r.Currency}
+		if err = rawAmounts(map[*[]byte]json.RawMessage{
+			&rate.Rate: r.Rate,
+			&rate.Min:  r.Min,
+			&rate.Max:  r.Max,
+		}); err != nil {
+			return nil, fmt.Errorf("rate %s %s: %w", r.DealType, r.Currency, err)
+		}
+		token.Rates = append(token.Rates, rate)
+	}
+	return token, nil
+}
+
+func rawAmounts(fields map[*[]byte]json.RawMessage) error {
+	for dst, raw := range fields {
+		value, err := rawAmount(raw)
+		if err != nil {
+			return err
+		}
+		*dst = value
+	}
+	return nil
+}
+
+func rawAmount(raw json.RawMessage) ([]byte, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	value, err := amount.FromPayload(raw)
+	if err != nil {
+		return nil, err
+	}
+	return amount.ToBytes(value), nil
+}
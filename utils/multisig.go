@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	pb "github.com/tickets-dao/integration/proto"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// AddMultisigFn - acl method to register multisig address, all members of signature policy must sign it
+	AddMultisigFn = "addMultisig"
+
+	aclChaincode = "acl"
+)
+
+// NewSignaturePolicy - N-of-M signature policy, public keys are sorted like foundation library does
+func NewSignaturePolicy(n int, publicKeys ...ed25519.PublicKey) (*pb.SignaturePolicy, error) {
+	if n <= 0 || n > len(publicKeys) {
+		return nil, fmt.Errorf("incorrect number of required signatures %d of %d keys", n, len(publicKeys))
+	}
+
+	keys := make([][]byte, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		if len(publicKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("incorrect public key length %d", len(publicKey))
+		}
+		keys[i] = publicKey
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	for i := 1; i < len(keys); i++ {
+		if bytes.Equal(keys[i-1], keys[i]) {
+			return nil, fmt.Errorf("duplicated public key %s", base58.Encode(keys[i]))
+		}
+	}
+
+	return &pb.SignaturePolicy{N: uint32(n), PubKeys: keys}, nil
+}
+
+// MultisigKey - sorted public keys of policy in base58 separated by '/', used as public key by acl checkKeys
+func MultisigKey(policy *pb.SignaturePolicy) string {
+	keys := make([]string, len(policy.PubKeys))
+	for i, publicKey := range policy.PubKeys {
+		keys[i] = base58.Encode(publicKey)
+	}
+	return strings.Join(keys, "/")
+}
+
+// GetMultisigAddress - address of multisig wallet, hash of sorted public keys in base58 check
+func GetMultisigAddress(policy *pb.SignaturePolicy) (string, error) {
+	if len(policy.PubKeys) == 0 {
+		return "", errors.New("policy has no public keys")
+	}
+
+	return Address(sha3.Sum256(bytes.Join(policy.PubKeys, nil))).String(), nil
+}
+
+// MultisigTx - arguments of chaincode method signed by members of multisig wallet.
+// Wire slice sent to hlf proxy is: empty slot, chaincode, channel, method args, nonce,
+// public keys of policy in sorted order, signatures in order of public keys.
+// Signature of member who didn't sign is empty string
+type MultisigTx struct {
+	Method     string
+	Chaincode  string
+	Channel    string
+	MethodArgs []string
+	Nonce      string
+	Policy     *pb.SignaturePolicy
+	Signatures [][]byte
+}
+
+// SignMultisigTx - sign arguments of method by signers, every signer must be member of policy and
+// number of signers must be enough for policy
+func SignMultisigTx(policy *pb.SignaturePolicy, signers []Signer, channel, chaincode, method, nonce string, args ...string) (*MultisigTx, error) {
+	if len(signers) < int(policy.N) {
+		return nil, fmt.Errorf("%d signers is not enough for policy %d of %d", len(signers), policy.N, len(policy.PubKeys))
+	}
+
+	tx := &MultisigTx{
+		Method:     method,
+		Chaincode:  chaincode,
+		Channel:    channel,
+		MethodArgs: args,
+		Nonce:      nonce,
+		Policy:     policy,
+		Signatures: make([][]byte, len(policy.PubKeys)),
+	}
+	for _, signer := range signers {
+		if err := tx.Sign(signer); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// Sign - add signature of policy member to transaction
+func (tx *MultisigTx) Sign(signer Signer) error {
+	i := tx.memberIndex(signer.PublicKey())
+	if i < 0 {
+		return fmt.Errorf("public key %s isn't member of policy", ConvertPublicKeyToBase58(signer.PublicKey()))
+	}
+
+	sig, err := signer.SignMessage(tx.Message())
+	if err != nil {
+		return fmt.Errorf("sign message: %w", err)
+	}
+	tx.Signatures[i] = sig
+	return nil
+}
+
+// Message - sha3 digest of method and wire slice without signatures, signed by every member
+func (tx *MultisigTx) Message() []byte {
+	unsigned := tx.Args()
+	message := sha3.Sum256([]byte(tx.Method + strings.Join(unsigned[:len(unsigned)-len(tx.Policy.PubKeys)], "")))
+	return message[:]
+}
+
+// Verify - check that every present signature is valid and there are enough of them for policy
+func (tx *MultisigTx) Verify() error {
+	if len(tx.Signatures) != len(tx.Policy.PubKeys) {
+		return fmt.Errorf("%d signatures for %d public keys", len(tx.Signatures), len(tx.Policy.PubKeys))
+	}
+
+	message := tx.Message()
+	signed := 0
+	for i, sig := range tx.Signatures {
+		if len(sig) == 0 {
+			continue
+		}
+		if !ed25519.Verify(tx.Policy.PubKeys[i], message, sig) {
+			return fmt.Errorf("incorrect signature of public key %s", base58.Encode(tx.Policy.PubKeys[i]))
+		}
+		signed++
+	}
+	if signed < int(tx.Policy.N) {
+		return fmt.Errorf("insufficient number of signatures %d, policy requires %d", signed, tx.Policy.N)
+	}
+	return nil
+}
+
+// Args - wire slice of transaction, arguments of Client.Invoke
+func (tx *MultisigTx) Args() []string {
+	args := make([]string, 0, len(tx.MethodArgs)+signedArgsServiceLen+2*len(tx.Policy.PubKeys))
+	args = append(args, "", tx.Chaincode, tx.Channel)
+	args = append(args, tx.MethodArgs...)
+	args = append(args, tx.Nonce)
+	for _, publicKey := range tx.Policy.PubKeys {
+		args = append(args, base58.Encode(publicKey))
+	}
+	for _, sig := range tx.Signatures {
+		args = append(args, base58.Encode(sig))
+	}
+	return args
+}
+
+func (tx *MultisigTx) memberIndex(publicKey ed25519.PublicKey) int {
+	for i, member := range tx.Policy.PubKeys {
+		if bytes.Equal(member, publicKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SignAddMultisig - arguments of acl addMultisig method signed by all members of policy:
+// empty slot, chaincode, channel, N, nonce, public keys in sorted order, signatures in order of public keys.
+// N of policy is signed like method argument, signatures of all members are required regardless of N
+func SignAddMultisig(policy *pb.SignaturePolicy, signers []Signer, nonce string) ([]string, error) {
+	if len(signers) != len(policy.PubKeys) {
+		return nil, fmt.Errorf("all %d members of policy must sign, got %d signers", len(policy.PubKeys), len(signers))
+	}
+
+	tx, err := SignMultisigTx(
+		&pb.SignaturePolicy{N: uint32(len(policy.PubKeys)), PubKeys: policy.PubKeys},
+		signers,
+		aclChaincode,
+		aclChaincode,
+		AddMultisigFn,
+		nonce,
+		strconv.FormatUint(uint64(policy.N), 10), //nolint:gomnd
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.Args(), nil
+}
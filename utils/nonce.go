@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// DefaultNonceSource - nonce source used by Sign
+var DefaultNonceSource NonceSource = NewMonotonicNonceSource(nil)
+
+// NonceSource - produces nonce for transaction signed by public key
+type NonceSource interface {
+	Next(publicKey ed25519.PublicKey) string
+}
+
+// NonceSourceFunc - adapter to use ordinary function as NonceSource
+type NonceSourceFunc func(publicKey ed25519.PublicKey) string
+
+// Next - call f(publicKey)
+func (f NonceSourceFunc) Next(publicKey ed25519.PublicKey) string {
+	return f(publicKey)
+}
+
+// Clock - source of current time
+type Clock func() time.Time
+
+// ShiftedClock - clock which is ahead of real time by offset, negative offset sets clock behind
+func ShiftedClock(offset time.Duration) Clock {
+	return func() time.Time {
+		return time.Now().Add(offset)
+	}
+}
+
+// NonceAt - nonce in format of foundation library, milliseconds of unix time
+func NonceAt(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10) //nolint:gomnd
+}
+
+// MonotonicNonceSource - thread-safe nonce source, nonces of every public key are strictly increasing
+// even if they are requested within one millisecond
+type MonotonicNonceSource struct {
+	clock Clock
+
+	mu   sync.Mutex
+	last map[string]int64
+}
+
+// NewMonotonicNonceSource - create nonce source based on clock, nil clock means time.Now
+func NewMonotonicNonceSource(clock Clock) *MonotonicNonceSource {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &MonotonicNonceSource{
+		clock: clock,
+		last:  make(map[string]int64),
+	}
+}
+
+// Next - current time of clock in milliseconds or previous nonce of public key plus one if it is not greater
+func (s *MonotonicNonceSource) Next(publicKey ed25519.PublicKey) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := string(publicKey)
+	nonce := s.clock().UnixMilli()
+	if last, ok := s.last[key]; ok && nonce <= last {
+		nonce = last + 1
+	}
+	s.last[key] = nonce
+
+	return strconv.FormatInt(nonce, 10) //nolint:gomnd
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// ChangePublicKeyFn - acl method to replace public key of user keeping its address, validators must sign it
+	ChangePublicKeyFn = "changePublicKey"
+	// ChangeMultisigPublicKeyFn - acl method to replace public key of multisig member keeping multisig address,
+	// validators must sign it
+	ChangeMultisigPublicKeyFn = "changeMultisigPublicKey"
+
+	// EnvValidatorPrivateKeys - private keys ed25519 of acl validators in base58 check separated by comma
+	EnvValidatorPrivateKeys = "VALIDATOR_PRIVATE_KEYS"
+)
+
+// ValidatorsFromEnv - signers of acl validators with keys from EnvValidatorPrivateKeys, empty if it isn't set
+func ValidatorsFromEnv() ([]Signer, error) {
+	env := os.Getenv(EnvValidatorPrivateKeys)
+	if env == "" {
+		return nil, nil
+	}
+
+	var validators []Signer
+	for _, encoded := range strings.Split(env, ",") {
+		privateKey, _, err := GetPrivateKeyFromBase58Check(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("validator private key from %s: %w", EnvValidatorPrivateKeys, err)
+		}
+		signer, err := NewKeySigner(privateKey)
+		if err != nil {
+			return nil, err
+		}
+		validators = append(validators, signer)
+	}
+	return validators, nil
+}
+
+// SignChangePublicKey - arguments of acl changePublicKey method signed by validators:
+// address, reason, reason id, new public key in base58, nonce, public keys of validators, signatures of validators
+func SignChangePublicKey(validators []Signer, address, reason string, reasonID int32, newPublicKey ed25519.PublicKey, nonce string) ([]string, error) {
+	return signByValidators(validators, ChangePublicKeyFn,
+		address, reason, strconv.FormatInt(int64(reasonID), 10), base58.Encode(newPublicKey), nonce)
+}
+
+// SignChangeMultisigPublicKey - arguments of acl changeMultisigPublicKey method signed by validators:
+// multisig address, old public key and new public key of member in base58, reason, reason id, nonce,
+// public keys of validators, signatures of validators
+func SignChangeMultisigPublicKey(validators []Signer, address string, oldPublicKey, newPublicKey ed25519.PublicKey, reason string, reasonID int32, nonce string) ([]string, error) {
+	return signByValidators(validators, ChangeMultisigPublicKeyFn,
+		address, base58.Encode(oldPublicKey), base58.Encode(newPublicKey), reason, strconv.FormatInt(int64(reasonID), 10), nonce)
+}
+
+// signByValidators - append public keys and signatures of validators to arguments,
+// message is sha3 digest of method and arguments
+func signByValidators(validators []Signer, method string, args ...string) ([]string, error) {
+	if len(validators) == 0 {
+		return nil, errors.New("no validators to sign")
+	}
+
+	message := sha3.Sum256([]byte(method + strings.Join(args, "")))
+	publicKeys := make([]string, 0, len(validators))
+	sigs := make([]string, 0, len(validators))
+	for _, validator := range validators {
+		sig, err := validator.SignMessage(message[:])
+		if err != nil {
+			return nil, fmt.Errorf("sign message: %w", err)
+		}
+		publicKeys = append(publicKeys, base58.Encode(validator.PublicKey()))
+		sigs = append(sigs, base58.Encode(sig))
+	}
+	return append(append(args, publicKeys...), sigs...), nil
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+// signedArgsServiceLen - number of service arguments in wire slice of SignedTx:
+// empty slot, chaincode, channel, nonce, public key and signature
+const signedArgsServiceLen = 6
+
+// Signer - signs message of transaction, message is sha3 digest of SignedTx
+type Signer interface {
+	PublicKey() ed25519.PublicKey
+	SignMessage(message []byte) ([]byte, error)
+}
+
+// KeySigner - Signer with ed25519 key pair in memory
+type KeySigner struct {
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewKeySigner - create Signer by ed25519 private key
+func NewKeySigner(privateKey ed25519.PrivateKey) (*KeySigner, error) {
+	publicKey, ok := privateKey.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("type assertion failed")
+	}
+	return &KeySigner{privateKey: privateKey, publicKey: publicKey}, nil
+}
+
+// PublicKey - public key of signer
+func (s *KeySigner) PublicKey() ed25519.PublicKey {
+	return s.publicKey
+}
+
+// SignMessage - sign message in ed25519 and check signature with public key
+func (s *KeySigner) SignMessage(message []byte) ([]byte, error) {
+	sig := ed25519.Sign(s.privateKey, message)
+	if !ed25519.Verify(s.publicKey, message, sig) {
+		return nil, errors.New("valid signature rejected")
+	}
+	return sig, nil
+}
+
+// SignedTx - signed arguments of chaincode method.
+// Wire slice sent to hlf proxy is: empty slot, chaincode, channel, method args, nonce, public key, signature.
+// Method isn't part of wire slice, it is sent as fcn, but it is part of signed message
+type SignedTx struct {
+	Method     string
+	Chaincode  string
+	Channel    string
+	MethodArgs []string
+	Nonce      string
+	PublicKey  ed25519.PublicKey
+	Signature  []byte
+}
+
+// SignTx - sign arguments of method by signer with nonce
+func SignTx(signer Signer, channel, chaincode, method, nonce string, args ...string) (*SignedTx, error) {
+	tx := &SignedTx{
+		Method:     method,
+		Chaincode:  chaincode,
+		Channel:    channel,
+		MethodArgs: args,
+		Nonce:      nonce,
+		PublicKey:  signer.PublicKey(),
+	}
+
+	if err := tx.Sign(signer); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Sign - set public key of signer and sign transaction again, used after fields are changed
+func (tx *SignedTx) Sign(signer Signer) error {
+	tx.PublicKey = signer.PublicKey()
+	sig, err := signer.SignMessage(tx.Message())
+	if err != nil {
+		return fmt.Errorf("sign message: %w", err)
+	}
+	tx.Signature = sig
+	return nil
+}
+
+// Message - sha3 digest of method and wire slice without signature, signed by Signer
+func (tx *SignedTx) Message() []byte {
+	unsigned := tx.Args()
+	message := sha3.Sum256([]byte(tx.Method + strings.Join(unsigned[:len(unsigned)-1], "")))
+	return message[:]
+}
+
+// Verify - check signature of transaction with its public key
+func (tx *SignedTx) Verify() error {
+	if len(tx.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("incorrect public key length %d", len(tx.PublicKey))
+	}
+	if !ed25519.Verify(tx.PublicKey, tx.Message(), tx.Signature) {
+		return errors.New("incorrect signature")
+	}
+	return nil
+}
+
+// Args - wire slice of transaction, arguments of Client.Invoke
+func (tx *SignedTx) Args() []string {
+	args := make([]string, 0, len(tx.MethodArgs)+signedArgsServiceLen)
+	args = append(args, "", tx.Chaincode, tx.Channel)
+	args = append(args, tx.MethodArgs...)
+	return append(args, tx.Nonce, ConvertPublicKeyToBase58(tx.PublicKey), base58.Encode(tx.Signature))
+}
+
+// ParseSignedArgs - decode wire slice of method produced by SignedTx.Args and verify its signature
+func ParseSignedArgs(method string, args []string) (*SignedTx, error) {
+	n := len(args)
+	if n < signedArgsServiceLen {
+		return nil, fmt.Errorf("incorrect number of arguments: %d, expected at least %d", n, signedArgsServiceLen)
+	}
+	if args[0] != "" {
+		return nil, fmt.Errorf("first argument must be empty, got %q", args[0])
+	}
+
+	tx := &SignedTx{
+		Method:     method,
+		Chaincode:  args[1],
+		Channel:    args[2],
+		MethodArgs: append([]string{}, args[3:n-3]...),
+		Nonce:      args[n-3],
+		PublicKey:  base58.Decode(args[n-2]),
+		Signature:  base58.Decode(args[n-1]),
+	}
+	if err := tx.Verify(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/tickets-dao/integration/proto"
+)
+
+// TestingT - part of testing.T, provider.T and provider.StepCtx used by assertion helpers
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+	FailNow()
+}
+
+// TxResult - batch event of transaction txID executed by robot in channel, waits until transaction is batched like WaitForTx.
+// Failure of transaction in batch isn't an error of TxResult, use TxEventError.
+// Error is ErrBatchEventsUnsupported if client is created without WithBatchEvents
+func (c *Client) TxResult(ctx context.Context, channel, txID string) (*pb.BatchTxEvent, error) {
+	if !c.batchEvents {
+		return nil, fmt.Errorf("batch event of tx %s in %s: %w", txID, channel, ErrBatchEventsUnsupported)
+	}
+
+	resp, err := Eventually(ctx, func(ctx context.Context) (*Response, error) {
+		return c.Query(ctx, channel, BatchTxEventFn, txID)
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("wait for tx %s in %s: %w", txID, channel, err)
+	}
+
+	event := &pb.BatchTxEvent{}
+	if err = proto.Unmarshal(resp.Payload, event); err != nil {
+		return nil, fmt.Errorf("unmarshal batch event of tx %s: %w", txID, err)
+	}
+	return event, nil
+}
+
+// TxEventError - failure of transaction in batch event as *TxError, nil if transaction succeeded
+func TxEventError(event *pb.BatchTxEvent) error {
+	if event.GetError() == nil {
+		return nil
+	}
+	return &TxError{
+		TxID:    hex.EncodeToString(event.Id),
+		Method:  event.Method,
+		Code:    event.Error.Code,
+		Message: event.Error.Error,
+	}
+}
+
+// RequireTxSucceeded - fail test now if transaction isn't batched or failed in batch.
+// Success can't be checked without batch events (see WithBatchEvents), so test fails with ErrBatchEventsUnsupported
+func RequireTxSucceeded(ctx context.Context, t TestingT, client *Client, channel, txID string) *pb.BatchTxEvent {
+	event, err := client.TxResult(ctx, channel, txID)
+	if err == nil {
+		err = TxEventError(event)
+	}
+	if err != nil {
+		t.Errorf("transaction %s in %s isn't succeeded: %v", txID, channel, err)
+		t.FailNow()
+	}
+	return event
+}
+
+// RequireTxFailedWith - fail test now if transaction isn't batched or isn't failed with error code,
+// error of failure must match target by errors.Is unless target is nil, see errors.go.
+// Failure can't be checked without batch events (see WithBatchEvents), so test fails with ErrBatchEventsUnsupported
+func RequireTxFailedWith(ctx context.Context, t TestingT, client *Client, channel, txID string, code int32, target error) {
+	event, err := client.TxResult(ctx, channel, txID)
+	if err != nil {
+		t.Errorf("transaction %s in %s: %v", txID, channel, err)
+		t.FailNow()
+		return
+	}
+
+	txErr := TxEventError(event)
+	if txErr == nil {
+		t.Errorf("transaction %s %s in %s succeeded, expected failure with code %d", txID, event.Method, channel, code)
+		t.FailNow()
+		return
+	}
+	if event.Error.Code != code {
+		t.Errorf("%v, expected code %d", txErr, code)
+		t.FailNow()
+		return
+	}
+	if target != nil && !errors.Is(txErr, target) {
+		t.Errorf("%v, expected %v", txErr, target)
+		t.FailNow()
+	}
+}
@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"strconv"
 	"time"
 )
 
@@ -20,7 +19,7 @@ const (
 	InvokeTimeout = 10 * time.Second
 	// QueryTimeout sets timeout for query method operations
 	QueryTimeout = 10 * time.Second
-	// QueryTimeout sets timeout for query method operations
+	// MoreNonceTTL - interval longer than nonce ttl of chaincode, nonce older than the latest one by MoreNonceTTL is rejected
 	MoreNonceTTL = 11 * time.Second
 )
 
@@ -31,7 +30,3 @@ func AsBytes(args ...string) [][]byte {
 	}
 	return bytes
 }
-
-func GetNonce() string {
-	return strconv.FormatInt(time.Now().UnixMilli(), 10) //nolint:gomnd
-}
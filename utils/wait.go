@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// BatchTxEventFn - query of fake hlf proxy service which returns batch event of transaction executed by robot.
+	// Query fails until transaction is batched, it is used only by client with WithBatchEvents
+	BatchTxEventFn = "getBatchTxEvent"
+	// WaitTimeout - time limit for Eventually and WaitForTx if context has no deadline
+	WaitTimeout = 10 * BatchTransactionTimeout
+	// PollInterval - initial interval between two polls, it doubles after every unsuccessful poll
+	PollInterval = 100 * time.Millisecond
+	// MaxPollInterval - upper limit of interval between two polls
+	MaxPollInterval = time.Second
+)
+
+var (
+	// ErrWaitTimeout - expected state was not reached before deadline
+	ErrWaitTimeout = errors.New("wait timeout")
+	// ErrBatchEventsUnsupported - batch event of transaction can't be queried, see WithBatchEvents
+	ErrBatchEventsUnsupported = errors.New("batch events are unsupported by hlf proxy service")
+)
+
+// QueryFunc - request which is repeated by Eventually until predicate is satisfied
+type QueryFunc func(ctx context.Context) (*Response, error)
+
+// Predicate - checks response of QueryFunc
+type Predicate func(resp *Response) bool
+
+// PayloadEquals - predicate is satisfied when response payload is equal to expected, example `"1"` for balance
+func PayloadEquals(expected string) Predicate {
+	return func(resp *Response) bool {
+		return resp != nil && bytes.Equal(resp.Payload, []byte(expected))
+	}
+}
+
+// Eventually - poll query with backoff until it returns no error and predicate is satisfied.
+// Nil predicate is satisfied by any successful response.
+// If ctx has no deadline WaitTimeout is used
+func Eventually(ctx context.Context, query QueryFunc, predicate Predicate) (*Response, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, WaitTimeout)
+		defer cancel()
+	}
+
+	var (
+		start    = time.Now()
+		interval = PollInterval
+		attempts int
+		lastResp *Response
+		lastErr  error
+	)
+	for {
+		attempts++
+		resp, err := query(ctx)
+		if err == nil && (predicate == nil || predicate(resp)) {
+			return resp, nil
+		}
+		lastResp, lastErr = resp, err
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, waitError(time.Since(start), attempts, lastResp, lastErr)
+		case <-timer.C:
+		}
+
+		if interval *= 2; interval > MaxPollInterval {
+			interval = MaxPollInterval
+		}
+	}
+}
+
+// WaitForTx - wait until transaction txID in chaincode cc is executed by robot in batch and its state is observed:
+// batch event is awaited first if client has batch events, then query is polled by Eventually until predicate is satisfied.
+// Query is required without batch events, because foundation has no query of transaction result
+func (c *Client) WaitForTx(ctx context.Context, cc, txID string, query QueryFunc, predicate Predicate) error {
+	if c.batchEvents {
+		if _, err := c.TxResult(ctx, cc, txID); err != nil {
+			return err
+		}
+	}
+	if query == nil {
+		if c.batchEvents {
+			return nil
+		}
+		return fmt.Errorf("wait for tx %s in %s without state query: %w", txID, cc, ErrBatchEventsUnsupported)
+	}
+
+	if _, err := Eventually(ctx, query, predicate); err != nil {
+		return fmt.Errorf("wait for state of tx %s in %s: %w", txID, cc, err)
+	}
+	return nil
+}
+
+func waitError(elapsed time.Duration, attempts int, lastResp *Response, lastErr error) error {
+	elapsed = elapsed.Round(time.Millisecond)
+	if lastErr != nil {
+		return fmt.Errorf("%w after %s and %d attempts, last error: %v", ErrWaitTimeout, elapsed, attempts, lastErr)
+	}
+	if lastResp != nil {
+		return fmt.Errorf("%w after %s and %d attempts, last payload: %s", ErrWaitTimeout, elapsed, attempts, lastResp.Payload)
+	}
+	return fmt.Errorf("%w after %s and %d attempts", ErrWaitTimeout, elapsed, attempts)
+}